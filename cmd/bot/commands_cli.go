@@ -0,0 +1,138 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"text/tabwriter"
+
+	"antartica-bot/internal/config"
+	"antartica-bot/internal/discord/commands"
+
+	"github.com/disgoorg/disgo"
+	"github.com/disgoorg/disgo/bot"
+	"github.com/disgoorg/snowflake/v2"
+	"github.com/spf13/cobra"
+)
+
+// newCommandsCmd adds `commands list|sync|purge` for managing slash commands without starting the bot.
+// Without --guild, list and sync work on the global commands.
+func newCommandsCmd(configPath string, logger *slog.Logger) *cobra.Command {
+	var guild string
+
+	root := &cobra.Command{
+		Use:   "commands",
+		Short: "Manage the bot's Discord slash commands",
+	}
+	root.PersistentFlags().StringVar(&guild, "guild", "", "guild ID to work on instead of the global commands")
+
+	root.AddCommand(&cobra.Command{
+		Use:   "list",
+		Short: "Show the commands Discord has and how they compare with this build",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, guildID, err := commandsClient(configPath, guild)
+			if err != nil {
+				return err
+			}
+			plan, err := commands.PlanCommands(client, guildID)
+			if err != nil {
+				return fmt.Errorf("fetch commands: %w", err)
+			}
+
+			out := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 4, 2, ' ', 0)
+			fmt.Fprintln(out, "NAME\tSTATUS\tID")
+			for _, change := range plan {
+				id := "-"
+				if change.ID != 0 {
+					id = change.ID.String()
+				}
+				fmt.Fprintf(out, "/%s\t%s\t%s\n", change.Name, change.Status, id)
+			}
+			return out.Flush()
+		},
+	})
+
+	root.AddCommand(&cobra.Command{
+		Use:   "sync",
+		Short: "Create, update and delete commands so Discord matches this build",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, guildID, err := commandsClient(configPath, guild)
+			if err != nil {
+				return err
+			}
+			plan, err := commands.SyncCommands(client, logger, guildID)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(
+				cmd.OutOrStdout(),
+				"%s: %d created, %d updated, %d deleted, %d unchanged\n",
+				commandScope(guildID),
+				plan.Count(commands.StatusNew),
+				plan.Count(commands.StatusChanged),
+				plan.Count(commands.StatusStale),
+				plan.Count(commands.StatusInSync),
+			)
+			return nil
+		},
+	})
+
+	root.AddCommand(&cobra.Command{
+		Use:   "purge",
+		Short: "Remove every command registered with a guild, such as a former dev guild",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if strings.TrimSpace(guild) == "" {
+				return errors.New("purge needs --guild")
+			}
+			client, guildID, err := commandsClient(configPath, guild)
+			if err != nil {
+				return err
+			}
+			removed, err := commands.ClearGuildCommands(client, logger, *guildID)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "%s: %d removed\n", commandScope(guildID), removed)
+			return nil
+		},
+	})
+
+	return root
+}
+
+// commandsClient builds a REST-only client from the config's token once the config validates. The gateway
+// is never opened.
+func commandsClient(configPath string, guild string) (bot.Client, *snowflake.ID, error) {
+	var guildID *snowflake.ID
+	if raw := strings.TrimSpace(guild); raw != "" {
+		parsed, err := snowflake.Parse(raw)
+		if err != nil {
+			return nil, nil, fmt.Errorf("--guild must be a guild ID: %w", err)
+		}
+		guildID = &parsed
+	}
+
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("load config %s: %w", configPath, err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, nil, err
+	}
+	client, err := disgo.New(strings.TrimSpace(cfg.Discord.Token))
+	if err != nil {
+		return nil, nil, err
+	}
+	return client, guildID, nil
+}
+
+func commandScope(guildID *snowflake.ID) string {
+	if guildID != nil {
+		return "guild " + guildID.String()
+	}
+	return "global"
+}
@@ -4,21 +4,29 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"log/slog"
+	"net"
 	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
 	"antartica-bot/internal/bus"
 	"antartica-bot/internal/config"
 	discordbridge "antartica-bot/internal/discord"
 	discordactions "antartica-bot/internal/discord/actions"
 	"antartica-bot/internal/discord/commands"
+	"antartica-bot/internal/health"
+	"antartica-bot/internal/metrics"
 	pbconsumers "antartica-bot/internal/pb/consumers"
 	pbhooks "antartica-bot/internal/pb/hooks"
+	pbmessages "antartica-bot/internal/pb/messages"
 	pbstores "antartica-bot/internal/pb/stores"
 
-	"github.com/disgoorg/snowflake/v2"
 	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/apis"
 	"github.com/pocketbase/pocketbase/core"
 )
 
@@ -27,84 +35,130 @@ func main() {
 	app := pocketbase.New()
 	eventBus := bus.New(bus.DefaultBuffer)
 
-	pbhooks.RegisterHooks(app, eventBus, logger)
-
-	args := os.Args[1:]
+	configPath, args := extractConfigPath(os.Args[1:])
 	commandArgs := args
 	if shouldDefaultServe(args) {
 		commandArgs = []string{"serve"}
 	}
 
 	if shouldStartDiscord(args) {
-		cfg, err := readConfig(logger)
+		cfg, err := readConfig(configPath, logger)
 		if err != nil {
 			if errors.Is(err, errConfigCreated) {
 				os.Exit(1)
 			}
-			logger.Error("config load failed", slog.Any("err", err))
+			logger.Error("config load failed", slog.String("path", configPath), slog.Any("err", err))
 			os.Exit(1)
 		}
-
-		token := strings.TrimSpace(cfg.Discord.Token)
-		if token == "" {
-			logger.Error("discord token missing from config")
+		if !validateConfig(cfg, logger) {
 			os.Exit(1)
 		}
 
-		if cfg.PocketBase.Port > 0 && isServeCommand(commandArgs) && !hasHTTPArg(commandArgs) {
-			commandArgs = append(commandArgs, fmt.Sprintf("--http=127.0.0.1:%d", cfg.PocketBase.Port))
+		// The level stays adjustable so config reloads can change it.
+		logLevel := new(slog.LevelVar)
+		logLevel.Set(parseLogLevel(cfg.Log.Level))
+		logger = newLogger(cfg.Log, os.Stderr, logLevel)
+		slog.SetDefault(logger)
+
+		// validateConfig has already parsed the intents.
+		intents, _ := discordbridge.ParseIntents(cfg.Discord.Intents)
+		pbmessages.SetDefaultLeaderboardTop(cfg.Leaderboard.DefaultTop)
+
+		eventBus, err = newEventBus(cfg.Bus, app.DataDir(), logger)
+		if err != nil {
+			logger.Error("event bus setup failed", slog.Any("err", err))
+			os.Exit(1)
 		}
 
-		var devGuildID *snowflake.ID
-		if cfg.Dev.Enabled {
-			rawGuildID := strings.TrimSpace(cfg.Dev.GuildID)
-			if rawGuildID == "" {
-				logger.Error("dev guild_id missing from config")
-				os.Exit(1)
-			}
-			parsed, err := snowflake.Parse(rawGuildID)
-			if err != nil {
-				logger.Error("dev guild_id invalid", slog.Any("err", err))
-				os.Exit(1)
-			}
-			devGuildID = &parsed
+		if cfg.PocketBase.Port > 0 && isServeCommand(commandArgs) && !hasHTTPArg(commandArgs) {
+			commandArgs = append(commandArgs, fmt.Sprintf("--http=%s", net.JoinHostPort(httpHost(cfg.PocketBase.Host), strconv.Itoa(cfg.PocketBase.Port))))
 		}
 
 		botConfig := discordbridge.DefaultConfig()
-		botConfig.Token = token
+		botConfig.Token = strings.TrimSpace(cfg.Discord.Token)
+		botConfig.Intents = intents
+		botConfig.MembersIntent = cfg.Discord.MembersIntent
+		botConfig.MessageContentIntent = cfg.Discord.MessageContentIntent
+		botConfig.MessageLog = cfg.Discord.MessageLog.Enabled
+		botConfig.MessageCacheSize = cfg.Discord.MessageLog.CacheSize
+		botConfig.Presence = presenceFromConfig(cfg.Discord.Presence)
 
 		roleToggleStore := pbstores.NewRoleToggleStore(app, logger)
 		reactionTrackStore := pbstores.NewReactionTrackStore(app, logger)
 		staticMessageStore := pbstores.NewStaticMessageStore(app, logger)
-		discordBot, err := discordbridge.New(botConfig, eventBus, logger, roleToggleStore, reactionTrackStore, staticMessageStore)
+		autoRoleStore := pbstores.NewAutoRoleStore(app, logger)
+		logRouteStore := pbstores.NewLogRouteStore(app, logger)
+		auditLogStore := pbstores.NewAuditLogStore(app, logger)
+		guildSettingsStore := pbstores.NewGuildSettingsStore(app, logger)
+		commandPermissionStore := pbstores.NewCommandPermissionStore(app, logger)
+		discordBot, err := discordbridge.New(botConfig, eventBus, logger, roleToggleStore, reactionTrackStore, staticMessageStore, autoRoleStore, logRouteStore, auditLogStore, guildSettingsStore, commandPermissionStore)
 		if err != nil {
 			logger.Error("failed to create discord client", slog.Any("err", err))
 			os.Exit(1)
 		}
 
+		eventBus.RoleDirectory = discordBot
+		eventBus.Outbox = pbstores.NewActionOutboxStore(app, logger)
+		eventBus.GuildSettings = guildSettingsStore
+
+		if err := metrics.RegisterBus(eventBus); err != nil {
+			logger.Warn("bus metrics registration failed", slog.Any("err", err))
+		}
+		if err := metrics.RegisterGateway(discordBot.Client()); err != nil {
+			logger.Warn("gateway metrics registration failed", slog.Any("err", err))
+		}
+		healthChecker := health.NewChecker(app, eventBus, discordBot)
+		configReloader := newReloader(configPath, cfg, logger, logLevel, discordBot, healthChecker)
+
 		ctx, cancel := context.WithCancel(context.Background())
+		var actionWorker *discordactions.Worker
 
 		app.OnServe().BindFunc(func(e *core.ServeEvent) error {
-			if err := commands.RegisterCommands(discordBot.Client(), logger, devGuildID); err != nil {
+			metricsRoute := e.Router.GET("/metrics", apis.WrapStdHandler(metrics.Handler()))
+			if !cfg.PocketBase.PublicMetrics {
+				metricsRoute.Bind(apis.RequireSuperuserAuth())
+			}
+			healthChecker.Bind(e.Router)
+
+			err := commands.RegisterCommands(discordBot.Client(), logger, devGuild(cfg.Dev))
+			if err != nil {
 				logger.Error("command registration failed", slog.Any("err", err))
 			}
+			healthChecker.SetCommandRegistration(err)
+			if guildID := formerDevGuild(cfg.Dev); guildID != nil && err == nil {
+				_, _ = commands.ClearGuildCommands(discordBot.Client(), logger, *guildID)
+			}
+			if err := pbconsumers.StartDiscordConsumer(ctx, app, eventBus, logger); err != nil {
+				return err
+			}
 			if err := discordBot.Start(context.Background()); err != nil {
 				return err
 			}
 
-			pbconsumers.StartDiscordConsumer(ctx, app, eventBus, logger)
-			discordactions.StartActionWorker(ctx, discordBot.Client(), eventBus, logger)
+			actionWorker = discordactions.StartActionWorker(ctx, discordBot.Client(), eventBus, logger, logRouteStore)
+			go config.Watch(ctx, configPath, config.DefaultWatchInterval, configReloader.Reload)
 
 			return e.Next()
 		})
 
 		app.OnTerminate().BindFunc(func(e *core.TerminateEvent) error {
+			shutdownTimeout := configReloader.Current().Bus.ShutdownTimeout
+			if shutdownTimeout <= 0 {
+				shutdownTimeout = 15 * time.Second
+			}
+			shutdown(eventBus, actionWorker, shutdownTimeout, logger)
 			cancel()
 			discordBot.Close(context.Background())
+			if err := eventBus.Close(); err != nil {
+				logger.Warn("event bus close failed", slog.Any("err", err))
+			}
 			return e.Next()
 		})
 	}
 
+	pbhooks.RegisterHooks(app, eventBus, logger)
+	app.RootCmd.AddCommand(newCommandsCmd(configPath, logger))
+
 	if len(commandArgs) > 0 {
 		app.RootCmd.SetArgs(commandArgs)
 	}
@@ -117,8 +171,141 @@ func main() {
 
 var errConfigCreated = errors.New("config.yaml created")
 
-func readConfig(logger *slog.Logger) (config.Config, error) {
-	const configFile = "config.yaml"
+// shutdown stops taking gateway events, lets the consumers drain what is queued into PocketBase,
+// then flushes pending actions to Discord. Both share one deadline; whatever is left is logged.
+func shutdown(eventBus *bus.Bus, actionWorker *discordactions.Worker, timeout time.Duration, logger *slog.Logger) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	eventBus.StopEvents()
+	logger.Info("draining queued events and actions", slog.Duration("timeout", timeout))
+
+	// Events still on disk under the spill policy are replayed on the next start; the rest are lost.
+	for subscriber, count := range eventBus.DrainEvents(ctx) {
+		logger.Warn("events left unhandled at shutdown", slog.String("subscriber", subscriber), slog.Int("count", count))
+	}
+
+	for _, action := range actionWorker.Drain(ctx) {
+		id, inner := bus.Unwrap(action)
+		logger.Warn(
+			"action left undelivered at shutdown",
+			slog.String("type", fmt.Sprintf("%T", inner)),
+			slog.String("outbox_id", id),
+			slog.Bool("replayed_on_start", id != ""),
+		)
+	}
+
+	// Actions delivered during the drain report their results as events, e.g. to mark discord_outbox rows sent.
+	for subscriber, count := range eventBus.DrainEvents(ctx) {
+		logger.Warn("action results left unhandled at shutdown", slog.String("subscriber", subscriber), slog.Int("count", count))
+	}
+
+	logger.Info("shutdown drain finished", slog.Uint64("rejected_events", eventBus.Stats().Rejected))
+}
+
+// newEventBus builds the bus from config. Gateway events spill to disk by default so a slow
+// database can't stall the gateway, while actions block briefly to apply backpressure to hooks.
+func newEventBus(cfg config.BusConfig, dataDir string, logger *slog.Logger) (*bus.Bus, error) {
+	spillDir := strings.TrimSpace(cfg.SpillDir)
+	if spillDir == "" {
+		spillDir = filepath.Join(dataDir, "bus")
+	}
+
+	return bus.NewWithOptions(bus.Options{
+		Events:   queueOptions(cfg.Events, cfg.Buffer, bus.OverflowSpill, 5*time.Second),
+		Actions:  queueOptions(cfg.Actions, cfg.Buffer, bus.OverflowBlock, 10*time.Second),
+		SpillDir: spillDir,
+		Logger:   logger,
+	})
+}
+
+func queueOptions(cfg config.QueueConfig, buffer int, policy bus.OverflowPolicy, timeout time.Duration) bus.QueueOptions {
+	if buffer <= 0 {
+		buffer = bus.DefaultBuffer
+	}
+	if raw := strings.TrimSpace(cfg.Policy); raw != "" {
+		policy = bus.OverflowPolicy(strings.ToLower(raw))
+	}
+	if cfg.PublishTimeout > 0 {
+		timeout = cfg.PublishTimeout
+	}
+	return bus.QueueOptions{
+		Buffer:         buffer,
+		Policy:         policy,
+		PublishTimeout: timeout,
+	}
+}
+
+// validateConfig logs every problem with cfg and reports whether it is usable.
+func validateConfig(cfg config.Config, logger *slog.Logger) bool {
+	var problems []string
+	if err := cfg.Validate(); err != nil {
+		var invalid *config.ValidationError
+		if !errors.As(err, &invalid) {
+			logger.Error("config invalid", slog.Any("err", err))
+			return false
+		}
+		problems = invalid.Problems
+	}
+	if _, err := discordbridge.ParseIntents(cfg.Discord.Intents); err != nil {
+		problems = append(problems, "discord.intents: "+err.Error())
+	}
+	for _, problem := range problems {
+		logger.Error("config invalid", slog.String("problem", problem))
+	}
+	return len(problems) == 0
+}
+
+// newLogger builds the process logger from the log section. Validate has already checked the format.
+func newLogger(cfg config.LogConfig, out io.Writer, level slog.Leveler) *slog.Logger {
+	opts := &slog.HandlerOptions{Level: level}
+	if strings.EqualFold(strings.TrimSpace(cfg.Format), "json") {
+		return slog.New(slog.NewJSONHandler(out, opts))
+	}
+	return slog.New(slog.NewTextHandler(out, opts))
+}
+
+func parseLogLevel(raw string) slog.Level {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(strings.TrimSpace(raw))); err != nil {
+		return slog.LevelInfo
+	}
+	return level
+}
+
+func httpHost(host string) string {
+	if host = strings.TrimSpace(host); host != "" {
+		return host
+	}
+	return "127.0.0.1"
+}
+
+const defaultConfigPath = "config.yaml"
+
+// extractConfigPath removes --config (or --config=path) from args, since PocketBase doesn't know the flag.
+// Without the flag the path comes from ANTARTICA_CONFIG, then defaults to config.yaml.
+func extractConfigPath(args []string) (string, []string) {
+	path := strings.TrimSpace(os.Getenv(config.ConfigPathEnv))
+	rest := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--config" && i+1 < len(args):
+			path = args[i+1]
+			i++
+		case strings.HasPrefix(arg, "--config="):
+			path = strings.TrimPrefix(arg, "--config=")
+		default:
+			rest = append(rest, arg)
+		}
+	}
+	if path == "" {
+		path = defaultConfigPath
+	}
+	return path, rest
+}
+
+func readConfig(configFile string, logger *slog.Logger) (config.Config, error) {
 	cfg, err := config.Load(configFile)
 	if err != nil {
 		if !os.IsNotExist(err) {
@@ -132,8 +319,8 @@ func readConfig(logger *slog.Logger) (config.Config, error) {
 			return cfg, err
 		}
 
-		logger.Info("created config.yaml from embedded config.example.yaml")
-		logger.Info("fill in discord.client_id, discord.secret, discord.token, and pocketbase.port; set dev.guild_id if dev.enabled")
+		logger.Info("created config file from embedded config.example.yaml", slog.String("path", configFile))
+		logger.Info("fill in discord.client_id, discord.secret, discord.token, and pocketbase.port; set dev.guild_id if dev.enabled. Any setting can also come from ANTARTICA_* environment variables")
 		return cfg, errConfigCreated
 	}
 	return cfg, nil
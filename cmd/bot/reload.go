@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"antartica-bot/internal/config"
+	discordbridge "antartica-bot/internal/discord"
+	"antartica-bot/internal/discord/commands"
+	"antartica-bot/internal/health"
+	pbmessages "antartica-bot/internal/pb/messages"
+
+	"github.com/disgoorg/snowflake/v2"
+)
+
+const reloadTimeout = 30 * time.Second
+
+// liveSettings are applied on reload. Changing anything else is reported as needing a restart.
+var liveSettings = map[string]bool{
+	"log.level":                      true,
+	"discord.presence.status":        true,
+	"discord.presence.activity_type": true,
+	"discord.presence.activity":      true,
+	"discord.message_log.enabled":    true,
+	"leaderboard.default_top":        true,
+	"bus.shutdown_timeout":           true,
+	"dev.enabled":                    true,
+	"dev.guild_id":                   true,
+}
+
+// reloader applies config changes while the bot runs. It compares against the applied config for live
+// settings and against the startup config for the rest, so reverting a change clears its restart warning.
+type reloader struct {
+	path     string
+	logger   *slog.Logger
+	logLevel *slog.LevelVar
+	bot      *discordbridge.Bot
+	health   *health.Checker
+
+	mu      sync.Mutex
+	started config.Config
+	current config.Config
+}
+
+func newReloader(path string, cfg config.Config, logger *slog.Logger, logLevel *slog.LevelVar, bot *discordbridge.Bot, checker *health.Checker) *reloader {
+	return &reloader{
+		path:     path,
+		logger:   logger,
+		logLevel: logLevel,
+		bot:      bot,
+		health:   checker,
+		started:  cfg,
+		current:  cfg,
+	}
+}
+
+func (r *reloader) Current() config.Config {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.current
+}
+
+// Reload reads the config again and applies it. A config that fails to load or validate is ignored.
+func (r *reloader) Reload(reason string) {
+	next, err := config.Load(r.path)
+	if err != nil {
+		r.logger.Warn("config reload failed; keeping the running config", slog.String("reason", reason), slog.String("path", r.path), slog.Any("err", err))
+		return
+	}
+	if !validateConfig(next, r.logger) {
+		r.logger.Warn("config reload rejected; keeping the running config", slog.String("reason", reason), slog.String("path", r.path))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), reloadTimeout)
+	defer cancel()
+	r.apply(ctx, reason, next)
+}
+
+func (r *reloader) apply(ctx context.Context, reason string, next config.Config) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	previous := r.current
+	var applied []string
+	changed := make(map[string]bool)
+	for _, path := range config.Changes(previous, next) {
+		changed[path] = true
+		if liveSettings[path] {
+			applied = append(applied, path)
+		}
+	}
+
+	if changed["log.level"] {
+		r.logLevel.Set(parseLogLevel(next.Log.Level))
+	}
+	if changed["leaderboard.default_top"] {
+		pbmessages.SetDefaultLeaderboardTop(next.Leaderboard.DefaultTop)
+	}
+	if changed["discord.presence.status"] || changed["discord.presence.activity_type"] || changed["discord.presence.activity"] {
+		if err := r.bot.SetPresence(ctx, presenceFromConfig(next.Discord.Presence)); err != nil {
+			r.logger.Warn("presence update failed", slog.Any("err", err))
+		}
+	}
+	if changed["discord.message_log.enabled"] {
+		if err := r.bot.SetMessageLog(next.Discord.MessageLog.Enabled); err != nil && !errors.Is(err, discordbridge.ErrMessageLogNeedsRestart) {
+			r.logger.Warn("message log toggle failed", slog.Any("err", err))
+		}
+	}
+	if changed["dev.enabled"] || changed["dev.guild_id"] {
+		r.reregisterCommands(devGuild(previous.Dev), devGuild(next.Dev))
+	}
+	r.current = next
+
+	var restart []string
+	for _, path := range config.Changes(r.started, next) {
+		needsRestart := !liveSettings[path]
+		if path == "discord.message_log.enabled" && !r.started.Discord.MessageLog.Enabled {
+			// The message log's intents and raw events are chosen when the gateway connects.
+			needsRestart = true
+		}
+		if needsRestart {
+			restart = append(restart, path)
+		}
+	}
+
+	r.logger.Info("config reloaded", slog.String("reason", reason), slog.Any("applied", applied))
+	if len(restart) > 0 {
+		r.logger.Warn("config changes need a restart to take effect", slog.Any("settings", restart))
+	}
+}
+
+// reregisterCommands moves slash commands when the dev guild changes. Once they are registered in their
+// new place, commands left in the old dev guild are cleared so they don't show up twice; if registration
+// fails they stay, so the commands remain usable somewhere. Global commands are left alone.
+func (r *reloader) reregisterCommands(previous *snowflake.ID, next *snowflake.ID) {
+	err := commands.RegisterCommands(r.bot.Client(), r.logger, next)
+	r.health.SetCommandRegistration(err)
+	if err == nil && previous != nil && (next == nil || *next != *previous) {
+		_, _ = commands.ClearGuildCommands(r.bot.Client(), r.logger, *previous)
+	}
+}
+
+// devGuild returns the guild to register commands with, or nil for global registration.
+// Validate has already checked the ID.
+func devGuild(cfg config.DevConfig) *snowflake.ID {
+	if !cfg.Enabled {
+		return nil
+	}
+	parsed, _ := snowflake.Parse(strings.TrimSpace(cfg.GuildID))
+	return &parsed
+}
+
+// formerDevGuild returns the dev guild while dev mode is off, so commands left there from earlier dev runs
+// can be cleared once they are registered globally.
+func formerDevGuild(cfg config.DevConfig) *snowflake.ID {
+	if cfg.Enabled {
+		return nil
+	}
+	parsed, err := snowflake.Parse(strings.TrimSpace(cfg.GuildID))
+	if err != nil || parsed == 0 {
+		return nil
+	}
+	return &parsed
+}
+
+func presenceFromConfig(cfg config.PresenceConfig) discordbridge.Presence {
+	return discordbridge.Presence{
+		Status:       cfg.Status,
+		ActivityType: cfg.ActivityType,
+		Activity:     cfg.Activity,
+	}
+}
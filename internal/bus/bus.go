@@ -2,6 +2,14 @@ package bus
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/disgoorg/disgo/discord"
@@ -10,20 +18,203 @@ import (
 
 const DefaultBuffer = 128
 
+// DrainPollInterval is how often drains, here and in the action worker, check whether queues are empty.
+const DrainPollInterval = 50 * time.Millisecond
+
+var ErrEventsStopped = errors.New("bus: events stopped")
+
+// Bus carries gateway events to the subscribers registered for their type, and actions to the Discord worker.
 type Bus struct {
-	DiscordEvents  chan DiscordEvent
-	DiscordActions chan DiscordAction
+	Actions *Queue[DiscordAction]
+
+	// RoleDirectory exposes Discord role data to PocketBase-side message builders. Nil when Discord isn't running.
+	RoleDirectory RoleDirectory
+	// Outbox persists actions before they are queued. Nil keeps actions in memory only.
+	Outbox ActionOutbox
+	// GuildSettings holds per-guild settings. Nil leaves every guild on the defaults.
+	GuildSettings GuildSettingsStore
+
+	logger        *slog.Logger
+	eventDefaults QueueOptions
+	spillDir      string
+	unrouted      atomic.Uint64
+	stopped       atomic.Bool
+	rejected      atomic.Uint64
+
+	mu          sync.RWMutex
+	subscribers []*Subscriber
+	routes      map[reflect.Type][]*Subscriber
+}
+
+type Options struct {
+	// Events holds the default queue options for subscribers.
+	Events  QueueOptions
+	Actions QueueOptions
+	// SpillDir holds the files of queues using the spill policy.
+	SpillDir string
+	Logger   *slog.Logger
 }
 
+// New creates a bus whose queues block when full.
 func New(buffer int) *Bus {
-	if buffer <= 0 {
-		buffer = DefaultBuffer
+	eventBus, _ := NewWithOptions(Options{
+		Events:  QueueOptions{Buffer: buffer},
+		Actions: QueueOptions{Buffer: buffer},
+	})
+	return eventBus
+}
+
+func NewWithOptions(opts Options) (*Bus, error) {
+	logger := opts.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	actionOpts := opts.Actions
+	if opts.SpillDir != "" {
+		actionOpts.SpillPath = filepath.Join(opts.SpillDir, "actions.spill")
+	}
+	actions, err := NewQueue[DiscordAction]("actions", actionOpts)
+	if err != nil {
+		return nil, err
 	}
 
 	return &Bus{
-		DiscordEvents:  make(chan DiscordEvent, buffer),
-		DiscordActions: make(chan DiscordAction, buffer),
+		Actions:       actions,
+		logger:        logger,
+		eventDefaults: opts.Events,
+		spillDir:      opts.SpillDir,
+		routes:        make(map[reflect.Type][]*Subscriber),
+	}, nil
+}
+
+// PublishEvent queues a gateway event for every subscriber handling its type. Failures are logged and returned.
+func (b *Bus) PublishEvent(ctx context.Context, event DiscordEvent) error {
+	if _, result := event.(ActionResult); b.stopped.Load() && !result {
+		b.rejected.Add(1)
+		return ErrEventsStopped
+	}
+
+	subscribers := b.subscribersFor(event)
+	if len(subscribers) == 0 {
+		b.unrouted.Add(1)
+		return nil
+	}
+
+	var errs []error
+	for _, subscriber := range subscribers {
+		if err := subscriber.queue.Publish(ctx, event); err != nil {
+			b.logger.Warn(
+				"discord event dropped",
+				slog.String("subscriber", subscriber.name),
+				slog.String("type", fmt.Sprintf("%T", event)),
+				slog.Any("err", err),
+			)
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// PublishAction queues an action for the Discord worker. With an outbox, the action is stored first
+// so it is replayed on the next start if it never reaches Discord. Failures are logged and returned.
+func (b *Bus) PublishAction(ctx context.Context, action DiscordAction) error {
+	if b.Outbox != nil && durable(action) {
+		id, err := b.Outbox.Add(ctx, action)
+		if err != nil {
+			b.logger.Warn("action outbox write failed", slog.String("type", fmt.Sprintf("%T", action)), slog.Any("err", err))
+		} else {
+			action = Outboxed{ID: id, Action: action}
+		}
+	}
+
+	if err := b.Actions.Publish(ctx, action); err != nil {
+		// An outboxed action stays pending and is retried on the next start.
+		id, inner := Unwrap(action)
+		b.logger.Warn("discord action dropped", slog.String("type", fmt.Sprintf("%T", inner)), slog.String("outbox_id", id), slog.Any("err", err))
+		return err
+	}
+	return nil
+}
+
+type Stats struct {
+	// Events sums the queues of all subscribers.
+	Events      QueueStats
+	Subscribers map[string]QueueStats
+	// Unrouted counts events published while nothing subscribed to their type.
+	Unrouted uint64
+	// Rejected counts events published after StopEvents.
+	Rejected uint64
+	// Handled counts events dispatched to handlers, by subscriber and then event type.
+	Handled map[string]map[string]uint64
+	Actions QueueStats
+}
+
+func (b *Bus) Stats() Stats {
+	b.mu.RLock()
+	subscribers := append([]*Subscriber(nil), b.subscribers...)
+	b.mu.RUnlock()
+
+	stats := Stats{
+		Subscribers: make(map[string]QueueStats, len(subscribers)),
+		Handled:     make(map[string]map[string]uint64, len(subscribers)),
+		Unrouted:    b.unrouted.Load(),
+		Rejected:    b.rejected.Load(),
+		Actions:     b.Actions.Stats(),
+	}
+	for _, subscriber := range subscribers {
+		queueStats := subscriber.Stats()
+		stats.Subscribers[subscriber.name] = queueStats
+		stats.Handled[subscriber.name] = subscriber.Handled()
+		stats.Events.Published += queueStats.Published
+		stats.Events.Dropped += queueStats.Dropped
+		stats.Events.Spilled += queueStats.Spilled
+		stats.Events.Depth += queueStats.Depth
 	}
+	return stats
+}
+
+// StopEvents makes PublishEvent reject new events, so DrainEvents can empty the subscriber queues.
+// ActionResults are still accepted, so actions delivered while draining report back to their callers.
+func (b *Bus) StopEvents() {
+	b.stopped.Store(true)
+}
+
+// DrainEvents waits until every subscriber has handled its queued events or ctx ends.
+// It returns the number of events each subscriber still had, keyed by name; nil means everything was handled.
+func (b *Bus) DrainEvents(ctx context.Context) map[string]int {
+	b.mu.RLock()
+	subscribers := append([]*Subscriber(nil), b.subscribers...)
+	b.mu.RUnlock()
+
+	ticker := time.NewTicker(DrainPollInterval)
+	defer ticker.Stop()
+
+	for {
+		var remaining map[string]int
+		for _, subscriber := range subscribers {
+			if left := subscriber.queue.Unfinished(); left > 0 {
+				if remaining == nil {
+					remaining = make(map[string]int)
+				}
+				remaining[subscriber.name] = left
+			}
+		}
+		if remaining == nil {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return remaining
+		case <-ticker.C:
+		}
+	}
+}
+
+// Close stops all queues. Spilled items stay on disk for the next start.
+func (b *Bus) Close() error {
+	return errors.Join(b.closeSubscribers(), b.Actions.Close())
 }
 
 type DiscordEvent interface {
@@ -46,6 +237,8 @@ type ReactionAdded struct {
 
 func (ReactionAdded) discordEvent() {}
 
+var _ = registerCodec(ReactionAdded{})
+
 type ReactionRemoved struct {
 	GuildID   snowflake.ID
 	ChannelID snowflake.ID
@@ -58,6 +251,8 @@ type ReactionRemoved struct {
 
 func (ReactionRemoved) discordEvent() {}
 
+var _ = registerCodec(ReactionRemoved{})
+
 type ReactionRemovedEmoji struct {
 	GuildID   snowflake.ID
 	ChannelID snowflake.ID
@@ -68,6 +263,8 @@ type ReactionRemovedEmoji struct {
 
 func (ReactionRemovedEmoji) discordEvent() {}
 
+var _ = registerCodec(ReactionRemovedEmoji{})
+
 type ReactionRemovedAll struct {
 	GuildID   snowflake.ID
 	ChannelID snowflake.ID
@@ -76,6 +273,8 @@ type ReactionRemovedAll struct {
 
 func (ReactionRemovedAll) discordEvent() {}
 
+var _ = registerCodec(ReactionRemovedAll{})
+
 type MessageDeleted struct {
 	GuildID   snowflake.ID
 	ChannelID snowflake.ID
@@ -84,6 +283,100 @@ type MessageDeleted struct {
 
 func (MessageDeleted) discordEvent() {}
 
+var _ = registerCodec(MessageDeleted{})
+
+type MemberJoined struct {
+	GuildID          snowflake.ID
+	UserID           snowflake.ID
+	Username         string
+	Bot              bool
+	Pending          bool
+	JoinedAt         time.Time
+	AccountCreatedAt time.Time
+}
+
+func (MemberJoined) discordEvent() {}
+
+var _ = registerCodec(MemberJoined{})
+
+type MemberUpdated struct {
+	GuildID        snowflake.ID
+	UserID         snowflake.ID
+	Username       string
+	Bot            bool
+	Pending        bool
+	WasPending     bool
+	AddedRoleIDs   []snowflake.ID
+	RemovedRoleIDs []snowflake.ID
+}
+
+func (MemberUpdated) discordEvent() {}
+
+var _ = registerCodec(MemberUpdated{})
+
+type MemberLeft struct {
+	GuildID  snowflake.ID
+	UserID   snowflake.ID
+	Username string
+	Bot      bool
+	RoleIDs  []snowflake.ID
+}
+
+func (MemberLeft) discordEvent() {}
+
+var _ = registerCodec(MemberLeft{})
+
+const (
+	MemberActionKick  = "kick"
+	MemberActionBan   = "ban"
+	MemberActionUnban = "unban"
+)
+
+// MemberModerated is emitted from audit log entries, so ActorID and Reason are set when Discord provides them.
+type MemberModerated struct {
+	GuildID snowflake.ID
+	UserID  snowflake.ID
+	ActorID snowflake.ID
+	Action  string
+	Reason  string
+}
+
+func (MemberModerated) discordEvent() {}
+
+var _ = registerCodec(MemberModerated{})
+
+type RoleUpdated struct {
+	GuildID    snowflake.ID
+	RoleID     snowflake.ID
+	Name       string
+	Manageable bool
+	Reason     string
+}
+
+func (RoleUpdated) discordEvent() {}
+
+var _ = registerCodec(RoleUpdated{})
+
+// RolesUpdated re-checks every role of a guild at once, after a change to the bot's own role. Consumers
+// refresh guild-wide state once for the batch rather than once per role.
+type RolesUpdated struct {
+	GuildID snowflake.ID
+	Roles   []RoleUpdated
+}
+
+func (RolesUpdated) discordEvent() {}
+
+var _ = registerCodec(RolesUpdated{})
+
+type RoleDeleted struct {
+	GuildID snowflake.ID
+	RoleID  snowflake.ID
+}
+
+func (RoleDeleted) discordEvent() {}
+
+var _ = registerCodec(RoleDeleted{})
+
 type InteractionReceived struct {
 	InteractionID   snowflake.ID
 	InteractionType discord.InteractionType
@@ -94,22 +387,187 @@ type InteractionReceived struct {
 
 func (InteractionReceived) discordEvent() {}
 
+var _ = registerCodec(InteractionReceived{})
+
+// ActionResult reports the outcome of an action that asked for one through its Reply, once it has
+// succeeded, failed for good or been superseded by a newer edit.
+type ActionResult struct {
+	Ref string
+	// ChannelID is the DM channel for SendDM and the new thread for CreateThread.
+	ChannelID snowflake.ID
+	MessageID snowflake.ID
+	// Error is empty when the action succeeded.
+	Error      string
+	Superseded bool
+}
+
+func (ActionResult) discordEvent() {}
+
+var _ = registerCodec(ActionResult{})
+
+// Reply asks for an action's result. Embed it in an action; the zero value asks for nothing.
+type Reply struct {
+	// Ref is echoed back in an ActionResult event published on the bus. It is persisted with the
+	// action, so results of actions replayed after a restart are still reported.
+	Ref string
+	// C receives the result in-process. It should be buffered: the worker drops the result rather
+	// than block. C is not persisted, so actions replayed from disk and actions abandoned at
+	// shutdown never report here; callers waiting on C need their own timeout.
+	C chan<- ActionResult `json:"-"`
+}
+
+func (r Reply) reply() Reply {
+	return r
+}
+
+// ReplyOf returns the Reply embedded in an action, unwrapping outboxed actions.
+func ReplyOf(action DiscordAction) Reply {
+	_, action = Unwrap(action)
+	if replier, ok := action.(interface{ reply() Reply }); ok {
+		return replier.reply()
+	}
+	return Reply{}
+}
+
 type SendMessage struct {
 	ChannelID snowflake.ID
 	Content   string
+	Embeds    []discord.Embed
+	// ReplyTo makes the message a reply. The message is still sent if the target was deleted.
+	ReplyTo snowflake.ID
+	Reply
 }
 
 func (SendMessage) discordAction() {}
 
+var _ = registerCodec(SendMessage{})
+
+// SendEmbed sends embeds with message components such as buttons and select menus.
+type SendEmbed struct {
+	ChannelID  snowflake.ID
+	Content    string
+	Embeds     []discord.Embed
+	Components []discord.ContainerComponent
+	ReplyTo    snowflake.ID
+	Reply
+}
+
+func (SendEmbed) discordAction() {}
+
+var _ = registerCodec(SendEmbed{})
+
+// UnmarshalJSON restores the component interfaces, which encoding/json can't do on its own.
+func (a *SendEmbed) UnmarshalJSON(data []byte) error {
+	type sendEmbed SendEmbed
+	var raw struct {
+		sendEmbed
+		Components []discord.UnmarshalComponent
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	*a = SendEmbed(raw.sendEmbed)
+	a.Components = make([]discord.ContainerComponent, 0, len(raw.Components))
+	for _, component := range raw.Components {
+		container, ok := component.Component.(discord.ContainerComponent)
+		if !ok {
+			return fmt.Errorf("bus: component %T is not a container component", component.Component)
+		}
+		a.Components = append(a.Components, container)
+	}
+	return nil
+}
+
 type EditMessage struct {
 	ChannelID snowflake.ID
 	MessageID snowflake.ID
 	Content   string
 	Embeds    []discord.Embed
+	Reply
 }
 
 func (EditMessage) discordAction() {}
 
+var _ = registerCodec(EditMessage{})
+
+// DeleteMessage succeeds when the message is already gone.
+type DeleteMessage struct {
+	ChannelID snowflake.ID
+	MessageID snowflake.ID
+	Reason    string
+	Reply
+}
+
+func (DeleteMessage) discordAction() {}
+
+var _ = registerCodec(DeleteMessage{})
+
+type AddReaction struct {
+	ChannelID snowflake.ID
+	MessageID snowflake.ID
+	// Emoji is a unicode emoji or name:id for a custom emoji.
+	Emoji string
+	Reply
+}
+
+func (AddReaction) discordAction() {}
+
+var _ = registerCodec(AddReaction{})
+
+type AddMemberRole struct {
+	GuildID snowflake.ID
+	UserID  snowflake.ID
+	RoleID  snowflake.ID
+	Reason  string
+	Reply
+}
+
+func (AddMemberRole) discordAction() {}
+
+var _ = registerCodec(AddMemberRole{})
+
+type RemoveMemberRole struct {
+	GuildID snowflake.ID
+	UserID  snowflake.ID
+	RoleID  snowflake.ID
+	Reason  string
+	Reply
+}
+
+func (RemoveMemberRole) discordAction() {}
+
+var _ = registerCodec(RemoveMemberRole{})
+
+// SendDM opens a DM channel with the user and sends the message. Users with closed DMs fail permanently.
+type SendDM struct {
+	UserID  snowflake.ID
+	Content string
+	Embeds  []discord.Embed
+	Reply
+}
+
+func (SendDM) discordAction() {}
+
+var _ = registerCodec(SendDM{})
+
+// CreateThread starts a thread from MessageID when set, otherwise a standalone thread in the channel.
+type CreateThread struct {
+	ChannelID snowflake.ID
+	MessageID snowflake.ID
+	Name      string
+	// Private only applies to standalone threads.
+	Private bool
+	// AutoArchive is in minutes (60, 1440, 4320 or 10080). Zero uses the channel default.
+	AutoArchive int
+	Reason      string
+	Reply
+}
+
+func (CreateThread) discordAction() {}
+
+var _ = registerCodec(CreateThread{})
+
 type LogLevel string
 
 const (
@@ -119,6 +577,20 @@ const (
 	LogError LogLevel = "error"
 )
 
+// Severity orders log levels from debug (0) to error (3). Unknown levels rank as info.
+func (l LogLevel) Severity() int {
+	switch l {
+	case LogDebug:
+		return 0
+	case LogWarn:
+		return 2
+	case LogError:
+		return 3
+	default:
+		return 1
+	}
+}
+
 type LogField struct {
 	Name   string
 	Value  string
@@ -137,6 +609,8 @@ type LogEvent struct {
 
 func (LogEvent) discordAction() {}
 
+var _ = registerCodec(LogEvent{})
+
 type ReactionTrack struct {
 	EmojiID     string
 	EmojiName   string
@@ -148,20 +622,134 @@ type RoleToggle struct {
 	RoleID      snowflake.ID
 	Permissions string
 	Description string
+	Category    string
+	Emoji       string
+	SortOrder   int
+}
+
+// RoleToggleDetails holds optional role toggle fields. Nil fields are left unchanged on update.
+type RoleToggleDetails struct {
+	Description *string
+	Category    *string
+	Emoji       *string
+	SortOrder   *int
 }
 
 type RoleToggleStore interface {
-	UpsertRoleToggle(ctx context.Context, guildID snowflake.ID, roleID snowflake.ID, permissions string, description *string) (bool, error)
+	UpsertRoleToggle(ctx context.Context, guildID snowflake.ID, roleID snowflake.ID, permissions string, details RoleToggleDetails) (bool, error)
 	RemoveRoleToggle(ctx context.Context, guildID snowflake.ID, roleID snowflake.ID) (int, error)
 	ListRoleToggles(ctx context.Context, guildID snowflake.ID) ([]RoleToggle, error)
 }
 
+const (
+	AutoRoleModeJoin   = "join"
+	AutoRoleModeSticky = "sticky"
+)
+
+type AutoRole struct {
+	RoleID         snowflake.ID
+	Mode           string
+	Delay          time.Duration
+	AfterScreening bool
+}
+
+type AutoRoleStore interface {
+	UpsertAutoRole(ctx context.Context, guildID snowflake.ID, roleID snowflake.ID, mode string, delay time.Duration, afterScreening bool) (bool, error)
+	RemoveAutoRole(ctx context.Context, guildID snowflake.ID, roleID snowflake.ID, mode string) (int, error)
+	ListAutoRoles(ctx context.Context, guildID snowflake.ID) ([]AutoRole, error)
+}
+
+type GuildRole struct {
+	ID       snowflake.ID
+	Name     string
+	Position int
+	// MemberCount is only meaningful when MemberCountKnown is set (requires the members intent).
+	MemberCount      int
+	MemberCountKnown bool
+}
+
+type RoleDirectory interface {
+	GuildRoles(ctx context.Context, guildID snowflake.ID) (map[snowflake.ID]GuildRole, error)
+}
+
 type ReactionTrackStore interface {
 	UpsertReactionTrack(ctx context.Context, guildID snowflake.ID, emojiID string, emojiName string, title string, description string) (bool, error)
 	RemoveReactionTrack(ctx context.Context, guildID snowflake.ID, emojiID string, emojiName string) (int, error)
 	ListReactionTracks(ctx context.Context, guildID snowflake.ID) ([]ReactionTrack, error)
 }
 
+type LogRoute struct {
+	ChannelID snowflake.ID
+	Category  string
+	MinLevel  LogLevel
+}
+
+type LogRouteStore interface {
+	UpsertLogRoute(ctx context.Context, guildID snowflake.ID, channelID snowflake.ID, category string, minLevel LogLevel) (bool, error)
+	// RemoveLogRoute removes the channel's route for category, or all of the channel's routes when category is empty.
+	RemoveLogRoute(ctx context.Context, guildID snowflake.ID, channelID snowflake.ID, category string) (int, error)
+	ListLogRoutes(ctx context.Context, guildID snowflake.ID) ([]LogRoute, error)
+}
+
+type AuditEntry struct {
+	GuildID   snowflake.ID
+	ActorID   snowflake.ID
+	ActorName string
+	Command   string
+	Options   map[string]string
+	Target    string
+	Outcome   string
+	Message   string
+	CreatedAt time.Time
+}
+
+type AuditFilter struct {
+	ActorID *snowflake.ID
+	// Command matches entries whose command path starts with this prefix, e.g. "/role".
+	Command string
+	Outcome string
+	Limit   int
+}
+
+type AuditLogStore interface {
+	RecordAudit(ctx context.Context, entry AuditEntry) error
+	ListAuditEntries(ctx context.Context, guildID snowflake.ID, filter AuditFilter) ([]AuditEntry, error)
+}
+
+// GuildSettingsStore keeps per-guild settings as key/value pairs. Keys and value formats are defined by the settings package.
+type GuildSettingsStore interface {
+	// GuildSettings returns the keys the guild has set. Unset keys are absent.
+	GuildSettings(ctx context.Context, guildID snowflake.ID) (map[string]string, error)
+	SetGuildSetting(ctx context.Context, guildID snowflake.ID, key string, value string, updatedBy snowflake.ID) error
+	// ResetGuildSetting removes the guild's value and reports whether one was set.
+	ResetGuildSetting(ctx context.Context, guildID snowflake.ID, key string) (bool, error)
+}
+
+type CommandRuleKind string
+
+const (
+	// CommandRuleDisabled turns a command path and everything below it off in the guild.
+	CommandRuleDisabled CommandRuleKind = "disabled"
+	// CommandRuleRole lets members with RoleID use a command path and everything below it without the
+	// command's default permissions.
+	CommandRuleRole CommandRuleKind = "role"
+)
+
+// CommandRule is a per-guild override for a command path such as /reaction or /role/auto/add.
+type CommandRule struct {
+	Path   string
+	Kind   CommandRuleKind
+	RoleID snowflake.ID
+}
+
+type CommandPermissionStore interface {
+	CommandRules(ctx context.Context, guildID snowflake.ID) ([]CommandRule, error)
+	// AddCommandRule stores the rule and reports whether it is new.
+	AddCommandRule(ctx context.Context, guildID snowflake.ID, rule CommandRule, createdBy snowflake.ID) (bool, error)
+	// RemoveCommandRule deletes the rule and reports whether it existed.
+	RemoveCommandRule(ctx context.Context, guildID snowflake.ID, rule CommandRule) (bool, error)
+}
+
 type StaticMessage struct {
 	ChannelID snowflake.ID
 	MessageID snowflake.ID
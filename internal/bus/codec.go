@@ -0,0 +1,60 @@
+package bus
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// codecTypes maps type names to the events and actions that can be encoded for storage.
+var codecTypes = map[string]reflect.Type{}
+
+// registerCodec makes value's type encodable. Every event and action registers itself right below its
+// marker method, so a new type can't be spilled or outboxed without being decodable on the next start.
+func registerCodec(value any) bool {
+	valueType := reflect.TypeOf(value)
+	codecTypes[valueType.Name()] = valueType
+	return true
+}
+
+type envelope struct {
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+// Encode serialises an event or action with its type name so Decode can restore the concrete type.
+func Encode(value any) ([]byte, error) {
+	valueType := reflect.TypeOf(value)
+	if valueType == nil {
+		return nil, fmt.Errorf("bus: cannot encode nil")
+	}
+	name := valueType.Name()
+	if _, ok := codecTypes[name]; !ok {
+		return nil, fmt.Errorf("bus: type %s is not registered", valueType)
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(envelope{Type: name, Data: data})
+}
+
+// Decode restores a value written by Encode.
+func Decode(raw []byte) (any, error) {
+	var wrapped envelope
+	if err := json.Unmarshal(raw, &wrapped); err != nil {
+		return nil, err
+	}
+
+	valueType, ok := codecTypes[wrapped.Type]
+	if !ok {
+		return nil, fmt.Errorf("bus: unknown type %q", wrapped.Type)
+	}
+
+	value := reflect.New(valueType)
+	if err := json.Unmarshal(wrapped.Data, value.Interface()); err != nil {
+		return nil, err
+	}
+	return value.Elem().Interface(), nil
+}
@@ -0,0 +1,85 @@
+package bus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// ActionOutbox persists actions until Discord has accepted them, so they survive restarts.
+type ActionOutbox interface {
+	// Add stores a pending action and returns its outbox ID.
+	Add(ctx context.Context, action DiscordAction) (string, error)
+	// Claim marks a pending action as in progress. It returns false if the action was already claimed or finished.
+	Claim(ctx context.Context, id string) (bool, error)
+	Complete(ctx context.Context, id string, note string) error
+	Fail(ctx context.Context, id string, reason string) error
+	// Recover returns actions left pending or in progress by a previous run, oldest first.
+	Recover(ctx context.Context) ([]OutboxEntry, error)
+	// Prune deletes finished actions once they are past their retention.
+	Prune(ctx context.Context)
+}
+
+type OutboxEntry struct {
+	ID     string
+	Action DiscordAction
+}
+
+// Outboxed is an action that has been written to the outbox. The worker reports its outcome back by ID.
+type Outboxed struct {
+	ID     string
+	Action DiscordAction
+}
+
+func (Outboxed) discordAction() {}
+
+var _ = registerCodec(Outboxed{})
+
+type outboxedJSON struct {
+	ID     string          `json:"id"`
+	Action json.RawMessage `json:"action"`
+}
+
+func (o Outboxed) MarshalJSON() ([]byte, error) {
+	action, err := Encode(o.Action)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(outboxedJSON{ID: o.ID, Action: action})
+}
+
+func (o *Outboxed) UnmarshalJSON(data []byte) error {
+	var raw outboxedJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	decoded, err := Decode(raw.Action)
+	if err != nil {
+		return err
+	}
+	action, ok := decoded.(DiscordAction)
+	if !ok {
+		return fmt.Errorf("bus: outboxed %T is not an action", decoded)
+	}
+	o.ID = raw.ID
+	o.Action = action
+	return nil
+}
+
+// Unwrap returns the outbox ID (empty when not outboxed) and the underlying action.
+func Unwrap(action DiscordAction) (string, DiscordAction) {
+	if outboxed, ok := action.(Outboxed); ok {
+		return outboxed.ID, outboxed.Action
+	}
+	return "", action
+}
+
+// durable reports whether an action is worth persisting. Log events are high volume and only informational.
+func durable(action DiscordAction) bool {
+	switch action.(type) {
+	case LogEvent, Outboxed:
+		return false
+	default:
+		return true
+	}
+}
@@ -0,0 +1,251 @@
+package bus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// OverflowPolicy decides what Publish does when a queue is full.
+type OverflowPolicy string
+
+const (
+	// OverflowBlock waits for room, bounded by the caller's context and the queue's publish timeout.
+	OverflowBlock OverflowPolicy = "block"
+	// OverflowDropOldest discards the oldest queued item to make room for the new one.
+	OverflowDropOldest OverflowPolicy = "drop_oldest"
+	// OverflowSpill appends items to a file on disk and feeds them back in order as room frees up.
+	// Publishes are serialised while items are on disk, so nothing overtakes a spilled item. Across a
+	// restart, outboxed actions are replayed before the spill file, so those two can still interleave.
+	OverflowSpill OverflowPolicy = "spill"
+)
+
+var ErrQueueFull = errors.New("bus: queue full")
+
+type QueueOptions struct {
+	Buffer int
+	Policy OverflowPolicy
+	// PublishTimeout bounds how long OverflowBlock waits when the caller's context has no deadline. Zero waits indefinitely.
+	PublishTimeout time.Duration
+	// SpillPath is the file used by OverflowSpill.
+	SpillPath string
+}
+
+type QueueStats struct {
+	// Published counts items accepted by the queue, including spilled ones.
+	Published uint64
+	// Dropped counts items discarded by drop_oldest or lost to a publish timeout or spill failure.
+	Dropped uint64
+	// Spilled counts items written to disk.
+	Spilled uint64
+	// Depth is the number of items waiting in memory plus on disk.
+	Depth int
+}
+
+// Queue is a buffered channel with an explicit overflow policy and counters.
+type Queue[T any] struct {
+	name    string
+	ch      chan T
+	policy  OverflowPolicy
+	timeout time.Duration
+	spill   *spillFile
+	// spillMu orders spill publishes: the check for items on disk and the send or write that follows
+	// happen together, so a publish can't slip into memory ahead of one being spilled.
+	spillMu sync.Mutex
+
+	published atomic.Uint64
+	dropped   atomic.Uint64
+	spilled   atomic.Uint64
+	// unfinished counts items accepted but not yet marked Done by the consumer.
+	unfinished atomic.Int64
+
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func NewQueue[T any](name string, opts QueueOptions) (*Queue[T], error) {
+	if opts.Buffer <= 0 {
+		opts.Buffer = DefaultBuffer
+	}
+	if opts.Policy == "" {
+		opts.Policy = OverflowBlock
+	}
+
+	queue := &Queue[T]{
+		name:    name,
+		ch:      make(chan T, opts.Buffer),
+		policy:  opts.Policy,
+		timeout: opts.PublishTimeout,
+		done:    make(chan struct{}),
+	}
+
+	switch opts.Policy {
+	case OverflowBlock, OverflowDropOldest:
+	case OverflowSpill:
+		if opts.SpillPath == "" {
+			return nil, fmt.Errorf("bus: queue %s uses spill without a spill path", name)
+		}
+		spill, err := openSpillFile(opts.SpillPath)
+		if err != nil {
+			return nil, fmt.Errorf("bus: queue %s: %w", name, err)
+		}
+		queue.spill = spill
+		queue.unfinished.Add(int64(spill.pending()))
+		go queue.drainSpill()
+	default:
+		return nil, fmt.Errorf("bus: queue %s has unknown overflow policy %q", name, opts.Policy)
+	}
+
+	return queue, nil
+}
+
+// C returns the channel consumers read from.
+func (q *Queue[T]) C() <-chan T {
+	return q.ch
+}
+
+// Publish enqueues an item according to the queue's overflow policy.
+func (q *Queue[T]) Publish(ctx context.Context, item T) error {
+	switch q.policy {
+	case OverflowDropOldest:
+		q.unfinished.Add(1)
+		for {
+			select {
+			case q.ch <- item:
+				q.published.Add(1)
+				return nil
+			default:
+			}
+			select {
+			case <-q.ch:
+				q.dropped.Add(1)
+				q.unfinished.Add(-1)
+			default:
+			}
+		}
+	case OverflowSpill:
+		q.unfinished.Add(1)
+		q.spillMu.Lock()
+		defer q.spillMu.Unlock()
+		// Items already on disk go first, so new ones follow them there to keep order.
+		if q.spill.pending() == 0 {
+			select {
+			case q.ch <- item:
+				q.published.Add(1)
+				return nil
+			default:
+			}
+		}
+		raw, err := Encode(item)
+		if err == nil {
+			err = q.spill.write(raw)
+		}
+		if err != nil {
+			q.dropped.Add(1)
+			q.unfinished.Add(-1)
+			return fmt.Errorf("bus: queue %s spill failed: %w", q.name, err)
+		}
+		q.spilled.Add(1)
+		q.published.Add(1)
+		return nil
+	default:
+		// Counted before the send so a consumer can't finish the item before it is counted.
+		q.unfinished.Add(1)
+		select {
+		case q.ch <- item:
+			q.published.Add(1)
+			return nil
+		default:
+		}
+
+		if _, ok := ctx.Deadline(); !ok && q.timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, q.timeout)
+			defer cancel()
+		}
+		select {
+		case q.ch <- item:
+			q.published.Add(1)
+			return nil
+		case <-ctx.Done():
+			q.dropped.Add(1)
+			q.unfinished.Add(-1)
+			return fmt.Errorf("bus: queue %s: %w: %w", q.name, ErrQueueFull, ctx.Err())
+		}
+	}
+}
+
+func (q *Queue[T]) Stats() QueueStats {
+	depth := len(q.ch)
+	if q.spill != nil {
+		depth += q.spill.pending()
+	}
+	return QueueStats{
+		Published: q.published.Load(),
+		Dropped:   q.dropped.Load(),
+		Spilled:   q.spilled.Load(),
+		Depth:     depth,
+	}
+}
+
+// Done marks an item received from C as handled.
+func (q *Queue[T]) Done() {
+	q.unfinished.Add(-1)
+}
+
+// Unfinished returns the number of items published but not yet marked Done, including items on disk.
+func (q *Queue[T]) Unfinished() int {
+	return int(q.unfinished.Load())
+}
+
+// Close stops feeding spilled items back. Items left on disk are replayed on the next start.
+func (q *Queue[T]) Close() error {
+	var err error
+	q.closeOnce.Do(func() {
+		close(q.done)
+		if q.spill != nil {
+			err = q.spill.close()
+		}
+	})
+	return err
+}
+
+func (q *Queue[T]) drainSpill() {
+	for {
+		select {
+		case <-q.done:
+			return
+		case <-q.spill.notify:
+		}
+
+		for q.spill.pending() > 0 {
+			raw, err := q.spill.next()
+			if err != nil {
+				// A corrupt spill file can't be recovered item by item; start over rather than spin.
+				lost := q.spill.discard()
+				q.dropped.Add(uint64(lost))
+				q.unfinished.Add(-int64(lost))
+				break
+			}
+
+			decoded, err := Decode(raw)
+			item, ok := decoded.(T)
+			if err != nil || !ok {
+				q.dropped.Add(1)
+				q.unfinished.Add(-1)
+				q.spill.ack()
+				continue
+			}
+
+			select {
+			case q.ch <- item:
+				q.spill.ack()
+			case <-q.done:
+				return
+			}
+		}
+	}
+}
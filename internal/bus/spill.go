@@ -0,0 +1,167 @@
+package bus
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// spillFile is an append-only file of newline-delimited encoded items.
+// Items are read back in order and the file is truncated once everything has been delivered.
+type spillFile struct {
+	path   string
+	notify chan struct{}
+
+	mu          sync.Mutex
+	file        *os.File
+	readOffset  int64
+	writeOffset int64
+	count       int
+	// nextLength is the size of the line returned by next, consumed on ack.
+	nextLength int64
+}
+
+func openSpillFile(path string) (*spillFile, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, err
+	}
+
+	spill := &spillFile{
+		path:   path,
+		notify: make(chan struct{}, 1),
+		file:   file,
+	}
+	if err := spill.recover(); err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	if spill.count > 0 {
+		spill.signal()
+	}
+	return spill, nil
+}
+
+// recover counts items left over from a previous run and drops a partially written trailing line.
+func (s *spillFile) recover() error {
+	data, err := io.ReadAll(s.file)
+	if err != nil {
+		return err
+	}
+
+	complete := bytes.LastIndexByte(data, '\n') + 1
+	if complete < len(data) {
+		if err := s.file.Truncate(int64(complete)); err != nil {
+			return err
+		}
+	}
+	s.writeOffset = int64(complete)
+	s.count = bytes.Count(data[:complete], []byte{'\n'})
+	return nil
+}
+
+func (s *spillFile) write(raw []byte) error {
+	line := append(append(make([]byte, 0, len(raw)+1), raw...), '\n')
+
+	s.mu.Lock()
+	written, err := s.file.WriteAt(line, s.writeOffset)
+	if err != nil {
+		// Cut off whatever part of the line made it to disk so the file stays line-aligned.
+		_ = s.file.Truncate(s.writeOffset)
+		s.mu.Unlock()
+		return err
+	}
+	s.writeOffset += int64(written)
+	s.count++
+	s.mu.Unlock()
+
+	s.signal()
+	return nil
+}
+
+// next returns the oldest undelivered item. It stays on disk until ack, so an interrupted delivery is replayed.
+func (s *spillFile) next() ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	reader := bufio.NewReader(io.NewSectionReader(s.file, s.readOffset, s.writeOffset-s.readOffset))
+	line, err := reader.ReadBytes('\n')
+	if err != nil {
+		return nil, err
+	}
+	s.nextLength = int64(len(line))
+	return line[:len(line)-1], nil
+}
+
+// ack marks the item returned by next as delivered.
+func (s *spillFile) ack() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.readOffset += s.nextLength
+	s.nextLength = 0
+	s.count--
+	if s.count <= 0 {
+		s.resetLocked()
+	}
+}
+
+// discard drops everything on disk and returns how many items were lost.
+func (s *spillFile) discard() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	lost := s.count
+	s.resetLocked()
+	return lost
+}
+
+func (s *spillFile) pending() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.count
+}
+
+// close compacts undelivered items to the start of the file so the next run replays only those.
+func (s *spillFile) close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.count > 0 && s.readOffset > 0 {
+		remaining := make([]byte, s.writeOffset-s.readOffset)
+		if _, err := s.file.ReadAt(remaining, s.readOffset); err != nil && err != io.EOF {
+			_ = s.file.Close()
+			return err
+		}
+		if err := os.WriteFile(s.path+".tmp", remaining, 0o600); err != nil {
+			_ = s.file.Close()
+			return err
+		}
+		if err := s.file.Close(); err != nil {
+			return err
+		}
+		return os.Rename(s.path+".tmp", s.path)
+	}
+	return s.file.Close()
+}
+
+func (s *spillFile) resetLocked() {
+	_ = s.file.Truncate(0)
+	s.readOffset = 0
+	s.writeOffset = 0
+	s.nextLength = 0
+	s.count = 0
+}
+
+func (s *spillFile) signal() {
+	select {
+	case s.notify <- struct{}{}:
+	default:
+	}
+}
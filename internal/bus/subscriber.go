@@ -0,0 +1,224 @@
+package bus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"reflect"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+)
+
+type SubscribeOptions struct {
+	// Concurrency is the number of workers draining the subscriber's queue. Events are handled
+	// in publish order only with a single worker, which is the default.
+	Concurrency int
+	// Buffer and Policy override the bus defaults for this subscriber's queue.
+	Buffer int
+	Policy OverflowPolicy
+}
+
+// Subscriber is a module's own queue of gateway events. It only receives the event types it handles.
+type Subscriber struct {
+	bus         *Bus
+	name        string
+	concurrency int
+	queue       *Queue[DiscordEvent]
+
+	mu       sync.RWMutex
+	handlers map[reflect.Type]func(context.Context, DiscordEvent)
+	started  bool
+	// handled maps event type names to *atomic.Uint64 counts of dispatched events, so counting
+	// doesn't contend on mu.
+	handled sync.Map
+}
+
+// NewSubscriber creates a named subscriber. Register handlers with Handle, then call Start.
+func (b *Bus) NewSubscriber(name string, opts SubscribeOptions) (*Subscriber, error) {
+	queueOpts := b.eventDefaults
+	if opts.Buffer > 0 {
+		queueOpts.Buffer = opts.Buffer
+	}
+	if opts.Policy != "" {
+		queueOpts.Policy = opts.Policy
+	}
+	if b.spillDir != "" {
+		queueOpts.SpillPath = filepath.Join(b.spillDir, "events-"+name+".spill")
+	}
+
+	queue, err := NewQueue[DiscordEvent]("events/"+name, queueOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	subscriber := &Subscriber{
+		bus:         b,
+		name:        name,
+		concurrency: concurrency,
+		queue:       queue,
+		handlers:    make(map[reflect.Type]func(context.Context, DiscordEvent)),
+	}
+
+	b.mu.Lock()
+	b.subscribers = append(b.subscribers, subscriber)
+	b.mu.Unlock()
+
+	return subscriber, nil
+}
+
+// Handle routes events of type T to the subscriber. Handling the DiscordEvent interface itself
+// subscribes to every event type.
+func Handle[T DiscordEvent](s *Subscriber, handler func(context.Context, T)) {
+	eventType := reflect.TypeOf((*T)(nil)).Elem()
+
+	s.mu.Lock()
+	s.handlers[eventType] = func(ctx context.Context, event DiscordEvent) {
+		handler(ctx, event.(T))
+	}
+	s.mu.Unlock()
+
+	s.bus.route(eventType, s)
+}
+
+// Start launches the subscriber's workers. They stop when ctx is cancelled.
+func (s *Subscriber) Start(ctx context.Context) {
+	s.mu.Lock()
+	if s.started {
+		s.mu.Unlock()
+		return
+	}
+	s.started = true
+	s.mu.Unlock()
+
+	for range s.concurrency {
+		go s.run(ctx)
+	}
+}
+
+func (s *Subscriber) Name() string {
+	return s.name
+}
+
+func (s *Subscriber) Stats() QueueStats {
+	return s.queue.Stats()
+}
+
+// Handled returns how many events of each type the subscriber has dispatched to a handler.
+func (s *Subscriber) Handled() map[string]uint64 {
+	handled := make(map[string]uint64)
+	s.handled.Range(func(eventType, count any) bool {
+		handled[eventType.(string)] = count.(*atomic.Uint64).Load()
+		return true
+	})
+	return handled
+}
+
+func (s *Subscriber) run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-s.queue.C():
+			if !ok {
+				return
+			}
+			s.dispatch(ctx, event)
+			s.queue.Done()
+		}
+	}
+}
+
+// dispatch runs the handler for the event's type, falling back to a catch-all handler.
+// A panicking handler is logged rather than taking down the other subscribers.
+func (s *Subscriber) dispatch(ctx context.Context, event DiscordEvent) {
+	s.mu.RLock()
+	eventType := reflect.TypeOf(event)
+	handler, ok := s.handlers[eventType]
+	if !ok {
+		handler, ok = s.handlers[discordEventType]
+	}
+	s.mu.RUnlock()
+	if !ok {
+		return
+	}
+
+	count, ok := s.handled.Load(eventType.Name())
+	if !ok {
+		count, _ = s.handled.LoadOrStore(eventType.Name(), new(atomic.Uint64))
+	}
+	count.(*atomic.Uint64).Add(1)
+
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			s.bus.logger.Error(
+				"event subscriber panicked",
+				slog.String("subscriber", s.name),
+				slog.String("type", fmt.Sprintf("%T", event)),
+				slog.Any("panic", recovered),
+				slog.String("stack", string(debug.Stack())),
+			)
+		}
+	}()
+	handler(ctx, event)
+}
+
+var discordEventType = reflect.TypeOf((*DiscordEvent)(nil)).Elem()
+
+func (b *Bus) route(eventType reflect.Type, subscriber *Subscriber) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, existing := range b.routes[eventType] {
+		if existing == subscriber {
+			return
+		}
+	}
+	b.routes[eventType] = append(b.routes[eventType], subscriber)
+}
+
+// subscribersFor returns the subscribers for an event type, including catch-all subscribers.
+func (b *Bus) subscribersFor(event DiscordEvent) []*Subscriber {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	typed := b.routes[reflect.TypeOf(event)]
+	all := b.routes[discordEventType]
+	if len(all) == 0 {
+		return typed
+	}
+
+	subscribers := make([]*Subscriber, 0, len(typed)+len(all))
+	subscribers = append(subscribers, typed...)
+	for _, subscriber := range all {
+		duplicate := false
+		for _, existing := range typed {
+			if existing == subscriber {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			subscribers = append(subscribers, subscriber)
+		}
+	}
+	return subscribers
+}
+
+func (b *Bus) closeSubscribers() error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	var errs []error
+	for _, subscriber := range b.subscribers {
+		errs = append(errs, subscriber.queue.Close())
+	}
+	return errors.Join(errs...)
+}
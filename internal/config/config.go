@@ -3,20 +3,108 @@ package config
 import (
 	"fmt"
 	"os"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
 
 type Config struct {
-	Discord    DiscordConfig    `yaml:"discord"`
-	PocketBase PocketBaseConfig `yaml:"pocketbase"`
-	Dev        DevConfig        `yaml:"dev"`
+	Discord     DiscordConfig     `yaml:"discord"`
+	PocketBase  PocketBaseConfig  `yaml:"pocketbase"`
+	Log         LogConfig         `yaml:"log"`
+	Bus         BusConfig         `yaml:"bus"`
+	Leaderboard LeaderboardConfig `yaml:"leaderboard"`
+	Dev         DevConfig         `yaml:"dev"`
+
+	// envProblems holds environment overrides that couldn't be applied. Validate reports them with the rest.
+	envProblems []string
+}
+
+// Default returns the settings used for anything config.yaml and the environment leave out.
+func Default() Config {
+	return Config{
+		Discord: DiscordConfig{
+			Presence: PresenceConfig{
+				Status:       "online",
+				ActivityType: "playing",
+			},
+		},
+		PocketBase: PocketBaseConfig{
+			Host: "127.0.0.1",
+		},
+		Log: LogConfig{
+			Level:  "info",
+			Format: "text",
+		},
+		Bus: BusConfig{
+			Buffer: 128,
+		},
+		Leaderboard: LeaderboardConfig{
+			DefaultTop: 10,
+		},
+	}
 }
 
 type DiscordConfig struct {
 	ClientID string `yaml:"client_id"`
 	Secret   string `yaml:"secret"`
 	Token    string `yaml:"token"`
+	// Intents lists gateway intents by name (e.g. guilds, guild_messages). Empty uses every non-privileged intent.
+	// Intents needed by enabled features are added either way.
+	Intents []string `yaml:"intents"`
+	// Presence is shown on the bot's profile.
+	Presence PresenceConfig `yaml:"presence"`
+	// MembersIntent enables the privileged Server Members intent (required for auto roles and member logging).
+	MembersIntent bool `yaml:"members_intent"`
+	// MessageContentIntent enables the privileged Message Content intent (required for message log content).
+	MessageContentIntent bool             `yaml:"message_content_intent"`
+	MessageLog           MessageLogConfig `yaml:"message_log"`
+}
+
+type MessageLogConfig struct {
+	// Enabled caches recent messages so edits and deletes can be logged with their content.
+	Enabled bool `yaml:"enabled"`
+	// CacheSize is the number of recent messages kept in memory across all guilds.
+	CacheSize int `yaml:"cache_size"`
+}
+
+type PresenceConfig struct {
+	// Status is online, idle, dnd or invisible.
+	Status string `yaml:"status"`
+	// ActivityType is playing, listening, watching, competing or custom.
+	ActivityType string `yaml:"activity_type"`
+	// Activity is the activity text. Empty shows no activity.
+	Activity string `yaml:"activity"`
+}
+
+type LogConfig struct {
+	// Level is debug, info, warn or error.
+	Level string `yaml:"level"`
+	// Format is text or json.
+	Format string `yaml:"format"`
+}
+
+type LeaderboardConfig struct {
+	// DefaultTop is how many members a leaderboard shows when it doesn't set its own top.
+	DefaultTop int `yaml:"default_top"`
+}
+
+type BusConfig struct {
+	// Buffer is the in-memory capacity of each queue.
+	Buffer  int         `yaml:"buffer"`
+	Events  QueueConfig `yaml:"events"`
+	Actions QueueConfig `yaml:"actions"`
+	// SpillDir holds items spilled by the "spill" policy. Defaults to <pb_data>/bus.
+	SpillDir string `yaml:"spill_dir"`
+	// ShutdownTimeout bounds how long shutdown waits for queued events and actions. Defaults to 15s.
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+}
+
+type QueueConfig struct {
+	// Policy is what happens when the queue is full: block, drop_oldest or spill.
+	Policy string `yaml:"policy"`
+	// PublishTimeout bounds how long the block policy waits for room.
+	PublishTimeout time.Duration `yaml:"publish_timeout"`
 }
 
 type DevConfig struct {
@@ -25,17 +113,29 @@ type DevConfig struct {
 }
 
 type PocketBaseConfig struct {
-	Port int `yaml:"port"`
+	// Host is the address the HTTP server binds to together with Port. Use 0.0.0.0 to listen on all interfaces.
+	Host string `yaml:"host"`
+	Port int    `yaml:"port"`
+	// PublicMetrics serves /metrics without authentication. Otherwise it needs a superuser token.
+	PublicMetrics bool `yaml:"public_metrics"`
 }
 
+// Load reads the config file at path and applies environment overrides on top. The file may be
+// missing when overrides are set, so a container can be configured from the environment alone.
+// Load doesn't validate; call Validate on the result, which also reports overrides that couldn't be applied.
 func Load(path string) (Config, error) {
-	var cfg Config
+	cfg := Default()
 	data, err := os.ReadFile(path)
-	if err != nil {
+	switch {
+	case err == nil:
+		if err := yaml.Unmarshal(data, &cfg); err != nil {
+			return cfg, fmt.Errorf("parse %s: %w", path, err)
+		}
+	case os.IsNotExist(err) && hasEnv():
+	default:
 		return cfg, err
 	}
-	if err := yaml.Unmarshal(data, &cfg); err != nil {
-		return cfg, fmt.Errorf("parse %s: %w", path, err)
-	}
+
+	_, cfg.envProblems = applyEnv(&cfg)
 	return cfg, nil
 }
@@ -0,0 +1,155 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EnvPrefix starts every environment override. The rest of the name is the field's yaml path in upper case,
+// joined by underscores: discord.token is ANTARTICA_DISCORD_TOKEN and bus.events.policy is ANTARTICA_BUS_EVENTS_POLICY.
+// Appending _FILE reads the value from a file instead, which suits mounted secrets: ANTARTICA_DISCORD_TOKEN_FILE.
+const EnvPrefix = "ANTARTICA_"
+
+// ConfigPathEnv names the config file when --config isn't given.
+const ConfigPathEnv = EnvPrefix + "CONFIG"
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// applyEnv overrides cfg fields from the environment. It returns the names of the variables it applied
+// and a problem for each one it couldn't, leaving those fields as they were.
+func applyEnv(cfg *Config) ([]string, []string) {
+	var applied, problems []string
+	_ = walkFields(reflect.ValueOf(cfg).Elem(), "", func(path string, field reflect.Value) error {
+		raw, source, ok, err := lookupEnv(envName(path))
+		if err != nil {
+			problems = append(problems, "environment override "+err.Error())
+			return nil
+		}
+		if !ok {
+			return nil
+		}
+		if err := setField(field, raw); err != nil {
+			problems = append(problems, fmt.Sprintf("environment override %s: %v", source, err))
+			return nil
+		}
+		applied = append(applied, source)
+		return nil
+	})
+	return applied, problems
+}
+
+// EnvNames lists every supported override variable, without the _FILE variants.
+func EnvNames() []string {
+	var names []string
+	var cfg Config
+	_ = walkFields(reflect.ValueOf(&cfg).Elem(), "", func(path string, _ reflect.Value) error {
+		names = append(names, envName(path))
+		return nil
+	})
+	return names
+}
+
+// hasEnv reports whether any override is set, so a config file isn't required.
+func hasEnv() bool {
+	for _, name := range EnvNames() {
+		if _, ok := os.LookupEnv(name); ok {
+			return true
+		}
+		if _, ok := os.LookupEnv(name + "_FILE"); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// envName maps a yaml path such as bus.events.policy to its override variable.
+func envName(path string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(path, ".", "_"))
+}
+
+// walkFields visits every leaf setting with its dotted yaml path.
+func walkFields(value reflect.Value, prefix string, visit func(path string, field reflect.Value) error) error {
+	valueType := value.Type()
+	for i := 0; i < valueType.NumField(); i++ {
+		fieldType := valueType.Field(i)
+		tag := strings.Split(fieldType.Tag.Get("yaml"), ",")[0]
+		if tag == "" || tag == "-" || !fieldType.IsExported() {
+			continue
+		}
+
+		path := tag
+		if prefix != "" {
+			path = prefix + "." + tag
+		}
+		field := value.Field(i)
+		if field.Kind() == reflect.Struct && field.Type() != durationType {
+			if err := walkFields(field, path, visit); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := visit(path, field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lookupEnv prefers the plain variable over its _FILE variant.
+func lookupEnv(name string) (string, string, bool, error) {
+	if raw, ok := os.LookupEnv(name); ok {
+		return raw, name, true, nil
+	}
+
+	fileVar := name + "_FILE"
+	path, ok := os.LookupEnv(fileVar)
+	if !ok || strings.TrimSpace(path) == "" {
+		return "", "", false, nil
+	}
+	data, err := os.ReadFile(strings.TrimSpace(path))
+	if err != nil {
+		return "", fileVar, false, fmt.Errorf("%s: %w", fileVar, err)
+	}
+	return strings.TrimRight(string(data), "\r\n"), fileVar, true, nil
+}
+
+func setField(field reflect.Value, raw string) error {
+	raw = strings.TrimSpace(raw)
+	switch {
+	case field.Type() == durationType:
+		duration, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(duration))
+	case field.Kind() == reflect.String:
+		field.SetString(raw)
+	case field.Kind() == reflect.Bool:
+		value, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		field.SetBool(value)
+	case field.Kind() == reflect.Int:
+		value, err := strconv.Atoi(raw)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(value))
+	case field.Kind() == reflect.Slice && field.Type().Elem().Kind() == reflect.String:
+		var values []string
+		for _, part := range strings.Split(raw, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				values = append(values, part)
+			}
+		}
+		field.Set(reflect.ValueOf(values))
+	default:
+		return fmt.Errorf("unsupported field type %s", field.Type())
+	}
+	return nil
+}
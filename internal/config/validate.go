@@ -0,0 +1,115 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// placeholderPrefix marks values copied unchanged from config.example.yaml.
+const placeholderPrefix = "YOUR_"
+
+// ValidationError lists every problem found in a config.
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	if len(e.Problems) == 1 {
+		return "invalid config: " + e.Problems[0]
+	}
+	return fmt.Sprintf("invalid config (%d problems): %s", len(e.Problems), strings.Join(e.Problems, "; "))
+}
+
+// Validate checks the settings the bot needs to start and reports all problems at once.
+// It returns nil or a *ValidationError.
+func (c Config) Validate() error {
+	problems := append([]string(nil), c.envProblems...)
+	add := func(format string, args ...any) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	token := strings.TrimSpace(c.Discord.Token)
+	switch {
+	case token == "":
+		add("discord.token is required (or set %sDISCORD_TOKEN)", EnvPrefix)
+	case strings.HasPrefix(token, placeholderPrefix):
+		add("discord.token still holds the example placeholder")
+	}
+	if raw := strings.TrimSpace(c.Discord.ClientID); raw != "" && !strings.HasPrefix(raw, placeholderPrefix) && !isSnowflake(raw) {
+		add("discord.client_id %q is not a valid Discord ID", raw)
+	}
+	if c.Discord.MessageLog.CacheSize < 0 {
+		add("discord.message_log.cache_size must not be negative")
+	}
+
+	switch strings.ToLower(strings.TrimSpace(c.Discord.Presence.Status)) {
+	case "", "online", "idle", "dnd", "invisible":
+	default:
+		add("discord.presence.status %q must be online, idle, dnd or invisible", c.Discord.Presence.Status)
+	}
+	switch strings.ToLower(strings.TrimSpace(c.Discord.Presence.ActivityType)) {
+	case "", "playing", "listening", "watching", "competing", "custom":
+	default:
+		add("discord.presence.activity_type %q must be playing, listening, watching, competing or custom", c.Discord.Presence.ActivityType)
+	}
+
+	if c.PocketBase.Port < 0 || c.PocketBase.Port > 65535 {
+		add("pocketbase.port %d is outside 0-65535", c.PocketBase.Port)
+	}
+
+	switch strings.ToLower(strings.TrimSpace(c.Log.Level)) {
+	case "", "debug", "info", "warn", "error":
+	default:
+		add("log.level %q must be debug, info, warn or error", c.Log.Level)
+	}
+	switch strings.ToLower(strings.TrimSpace(c.Log.Format)) {
+	case "", "text", "json":
+	default:
+		add("log.format %q must be text or json", c.Log.Format)
+	}
+
+	if c.Leaderboard.DefaultTop < 0 {
+		add("leaderboard.default_top must not be negative")
+	}
+
+	if c.Bus.Buffer < 0 {
+		add("bus.buffer must not be negative")
+	}
+	for _, queue := range []struct {
+		name   string
+		config QueueConfig
+	}{{"events", c.Bus.Events}, {"actions", c.Bus.Actions}} {
+		switch strings.ToLower(strings.TrimSpace(queue.config.Policy)) {
+		case "", "block", "drop_oldest", "spill":
+		default:
+			add("bus.%s.policy %q must be block, drop_oldest or spill", queue.name, queue.config.Policy)
+		}
+		if queue.config.PublishTimeout < 0 {
+			add("bus.%s.publish_timeout must not be negative", queue.name)
+		}
+	}
+	if c.Bus.ShutdownTimeout < 0 {
+		add("bus.shutdown_timeout must not be negative")
+	}
+
+	if c.Dev.Enabled {
+		raw := strings.TrimSpace(c.Dev.GuildID)
+		switch {
+		case raw == "":
+			add("dev.guild_id is required when dev.enabled is true")
+		case !isSnowflake(raw):
+			add("dev.guild_id %q is not a valid Discord ID", raw)
+		}
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+	return &ValidationError{Problems: problems}
+}
+
+func isSnowflake(raw string) bool {
+	_, err := strconv.ParseUint(raw, 10, 64)
+	return err == nil
+}
@@ -0,0 +1,84 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"os"
+	"os/signal"
+	"reflect"
+	"syscall"
+	"time"
+)
+
+// DefaultWatchInterval is how often Watch checks the config file for changes.
+const DefaultWatchInterval = 2 * time.Second
+
+// Reload reasons passed to Watch's callback.
+const (
+	ReloadFileChanged = "file changed"
+	ReloadSignal      = "SIGHUP"
+)
+
+// Watch calls reload when the file at path changes or the process receives SIGHUP, until ctx is done.
+// The file is polled rather than watched so editors that replace it on save are picked up too.
+// A missing file is not an error: SIGHUP still reloads, which suits configs built from the environment.
+func Watch(ctx context.Context, path string, interval time.Duration, reload func(reason string)) {
+	if interval <= 0 {
+		interval = DefaultWatchInterval
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+	defer signal.Stop(signals)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	last := fingerprint(path)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-signals:
+			last = fingerprint(path)
+			reload(ReloadSignal)
+		case <-ticker.C:
+			// Touching the file without changing it doesn't count as a change.
+			current := fingerprint(path)
+			if bytes.Equal(current, last) {
+				continue
+			}
+			last = current
+			reload(ReloadFileChanged)
+		}
+	}
+}
+
+// fingerprint hashes the file's contents, or returns nil when it can't be read.
+func fingerprint(path string) []byte {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	sum := sha256.Sum256(data)
+	return sum[:]
+}
+
+// Changes lists the yaml paths of settings that differ between old and updated, in declaration order.
+func Changes(old Config, updated Config) []string {
+	before := make(map[string]any)
+	_ = walkFields(reflect.ValueOf(&old).Elem(), "", func(path string, field reflect.Value) error {
+		before[path] = field.Interface()
+		return nil
+	})
+
+	var changed []string
+	_ = walkFields(reflect.ValueOf(&updated).Elem(), "", func(path string, field reflect.Value) error {
+		if !reflect.DeepEqual(before[path], field.Interface()) {
+			changed = append(changed, path)
+		}
+		return nil
+	})
+	return changed
+}
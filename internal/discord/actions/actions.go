@@ -4,57 +4,167 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"net/http"
 	"strings"
+	"time"
 
 	"antartica-bot/internal/bus"
 
 	"github.com/disgoorg/disgo/bot"
 	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/rest"
 )
 
-func StartActionWorker(ctx context.Context, client bot.Client, eventBus *bus.Bus, logger *slog.Logger) {
+// Worker delivers actions from the bus. See StartActionWorker.
+type Worker struct {
+	queue *bus.Queue[bus.DiscordAction]
+	pool  *workerPool
+}
+
+// StartActionWorker runs actions from the bus on a pool of workers. Actions for the same channel
+// (or guild, for member changes) run in order; different channels run in parallel.
+func StartActionWorker(ctx context.Context, client bot.Client, eventBus *bus.Bus, logger *slog.Logger, logRouteStore bus.LogRouteStore) *Worker {
 	if eventBus == nil {
-		return
+		return nil
 	}
 	if logger == nil {
 		logger = slog.Default()
 	}
 
+	sink := newLogSink(ctx, client, logRouteStore, eventBus.GuildSettings, logger)
+	pool := newWorkerPool(client, eventBus, logger, sink, defaultActionWorkers)
+
 	go func() {
+		replayOutbox(ctx, eventBus.Outbox, pool, logger)
+		pruneOutbox(ctx, eventBus.Outbox)
+
+		var prune <-chan time.Time
+		if eventBus.Outbox != nil {
+			ticker := time.NewTicker(outboxPruneInterval)
+			defer ticker.Stop()
+			prune = ticker.C
+		}
+
 		for {
 			select {
 			case <-ctx.Done():
 				return
-			case action, ok := <-eventBus.DiscordActions:
+			case <-prune:
+				pruneOutbox(ctx, eventBus.Outbox)
+			case action, ok := <-eventBus.Actions.C():
 				if !ok {
 					return
 				}
-				handleAction(ctx, client, logger, action)
+				if !pool.dispatch(ctx, action) {
+					logger.Warn("discord action dropped at shutdown", slog.String("type", fmt.Sprintf("%T", action)))
+				}
+				// The pool tracks the action from here, so the queue can count it as handled.
+				eventBus.Actions.Done()
 			}
 		}
 	}()
+
+	return &Worker{queue: eventBus.Actions, pool: pool}
+}
+
+// Drain waits until every queued and running action has finished and batched log messages are posted,
+// or ctx ends, and returns the actions that were still undelivered. Outboxed ones are replayed on the
+// next start; log batches still waiting are reported and lost.
+func (w *Worker) Drain(ctx context.Context) []bus.DiscordAction {
+	if w == nil {
+		return nil
+	}
+
+	ticker := time.NewTicker(bus.DrainPollInterval)
+	defer ticker.Stop()
+
+	for {
+		// Log batches are posted once nothing else is running, since running actions may still add to them.
+		if w.queue.Unfinished() == 0 && w.pool.idle() && !w.pool.sink.flushAll(ctx) {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			if pending := w.pool.sink.pendingEmbeds(); pending > 0 {
+				w.pool.logger.Warn("log messages left unposted at shutdown", slog.Int("embeds", pending))
+			}
+			return append(w.pool.undelivered(), w.queued()...)
+		case <-ticker.C:
+		}
+	}
+}
+
+// queued takes whatever is still buffered in memory. Spilled actions stay on disk for the next start.
+func (w *Worker) queued() []bus.DiscordAction {
+	var actions []bus.DiscordAction
+	for {
+		select {
+		case action := <-w.queue.C():
+			w.queue.Done()
+			actions = append(actions, action)
+		default:
+			return actions
+		}
+	}
+}
+
+// replayOutbox queues actions a previous run stored but never delivered.
+func replayOutbox(ctx context.Context, outbox bus.ActionOutbox, pool *workerPool, logger *slog.Logger) {
+	if outbox == nil {
+		return
+	}
+
+	entries, err := outbox.Recover(ctx)
+	if err != nil {
+		logger.Error("action outbox recovery failed", slog.Any("err", err))
+		return
+	}
+	if len(entries) > 0 {
+		logger.Info("replaying pending actions", slog.Int("count", len(entries)))
+	}
+	for _, entry := range entries {
+		if !pool.dispatch(ctx, bus.Outboxed{ID: entry.ID, Action: entry.Action}) {
+			return
+		}
+	}
 }
 
-func handleAction(ctx context.Context, client bot.Client, logger *slog.Logger, action bus.DiscordAction) {
+// pruneOutbox drops finished outbox entries past their retention, so the table doesn't grow between restarts.
+func pruneOutbox(ctx context.Context, outbox bus.ActionOutbox) {
+	if outbox != nil {
+		outbox.Prune(ctx)
+	}
+}
+
+// executeAction performs a single action and returns the channel and message it created or touched.
+// Returned errors are classified by the worker for retries.
+func executeAction(ctx context.Context, client bot.Client, logger *slog.Logger, sink *logSink, action bus.DiscordAction) (bus.ActionResult, error) {
 	switch payload := action.(type) {
 	case bus.SendMessage:
-		if payload.Content == "" {
-			return
+		if payload.Content == "" && len(payload.Embeds) == 0 {
+			return bus.ActionResult{}, nil
 		}
-		_, err := client.Rest().CreateMessage(
-			payload.ChannelID,
-			discord.NewMessageCreateBuilder().SetContent(payload.Content).Build(),
-		)
-		if err != nil {
-			logger.Error(
-				"discord send message failed",
-				slog.Any("err", err),
-				slog.String("channel_id", payload.ChannelID.String()),
-			)
+		builder := discord.NewMessageCreateBuilder().SetContent(payload.Content).SetEmbeds(payload.Embeds...)
+		if payload.ReplyTo != 0 {
+			builder.SetMessageReference(&discord.MessageReference{MessageID: &payload.ReplyTo})
 		}
+		return messageResult(client.Rest().CreateMessage(payload.ChannelID, builder.Build(), rest.WithCtx(ctx)))
+	case bus.SendEmbed:
+		if payload.Content == "" && len(payload.Embeds) == 0 {
+			return bus.ActionResult{}, nil
+		}
+		builder := discord.NewMessageCreateBuilder().
+			SetContent(payload.Content).
+			SetEmbeds(payload.Embeds...).
+			SetContainerComponents(payload.Components...)
+		if payload.ReplyTo != 0 {
+			builder.SetMessageReference(&discord.MessageReference{MessageID: &payload.ReplyTo})
+		}
+		return messageResult(client.Rest().CreateMessage(payload.ChannelID, builder.Build(), rest.WithCtx(ctx)))
 	case bus.EditMessage:
 		if payload.ChannelID == 0 || payload.MessageID == 0 {
-			return
+			return bus.ActionResult{}, nil
 		}
 		update := discord.MessageUpdate{}
 		if payload.Content != "" {
@@ -63,20 +173,102 @@ func handleAction(ctx context.Context, client bot.Client, logger *slog.Logger, a
 		if len(payload.Embeds) > 0 {
 			update.Embeds = &payload.Embeds
 		}
-		_, err := client.Rest().UpdateMessage(payload.ChannelID, payload.MessageID, update)
+		return messageResult(client.Rest().UpdateMessage(payload.ChannelID, payload.MessageID, update, rest.WithCtx(ctx)))
+	case bus.DeleteMessage:
+		if payload.ChannelID == 0 || payload.MessageID == 0 {
+			return bus.ActionResult{}, nil
+		}
+		err := client.Rest().DeleteMessage(payload.ChannelID, payload.MessageID, requestOpts(ctx, payload.Reason)...)
+		if isStatus(err, http.StatusNotFound) {
+			err = nil
+		}
+		return bus.ActionResult{ChannelID: payload.ChannelID, MessageID: payload.MessageID}, err
+	case bus.AddReaction:
+		if payload.ChannelID == 0 || payload.MessageID == 0 || payload.Emoji == "" {
+			return bus.ActionResult{}, nil
+		}
+		err := client.Rest().AddReaction(payload.ChannelID, payload.MessageID, payload.Emoji, rest.WithCtx(ctx))
+		return bus.ActionResult{ChannelID: payload.ChannelID, MessageID: payload.MessageID}, err
+	case bus.AddMemberRole:
+		if payload.GuildID == 0 || payload.UserID == 0 || payload.RoleID == 0 {
+			return bus.ActionResult{}, nil
+		}
+		return bus.ActionResult{}, client.Rest().AddMemberRole(payload.GuildID, payload.UserID, payload.RoleID, requestOpts(ctx, payload.Reason)...)
+	case bus.RemoveMemberRole:
+		if payload.GuildID == 0 || payload.UserID == 0 || payload.RoleID == 0 {
+			return bus.ActionResult{}, nil
+		}
+		return bus.ActionResult{}, client.Rest().RemoveMemberRole(payload.GuildID, payload.UserID, payload.RoleID, requestOpts(ctx, payload.Reason)...)
+	case bus.SendDM:
+		if payload.UserID == 0 || (payload.Content == "" && len(payload.Embeds) == 0) {
+			return bus.ActionResult{}, nil
+		}
+		channel, err := client.Rest().CreateDMChannel(payload.UserID, rest.WithCtx(ctx))
 		if err != nil {
-			logger.Error(
-				"discord update message failed",
-				slog.Any("err", err),
-				slog.String("channel_id", payload.ChannelID.String()),
-				slog.String("message_id", payload.MessageID.String()),
-			)
+			return bus.ActionResult{}, err
 		}
+		message := discord.NewMessageCreateBuilder().SetContent(payload.Content).SetEmbeds(payload.Embeds...).Build()
+		return messageResult(client.Rest().CreateMessage(channel.ID(), message, rest.WithCtx(ctx)))
+	case bus.CreateThread:
+		if payload.ChannelID == 0 || strings.TrimSpace(payload.Name) == "" {
+			return bus.ActionResult{}, nil
+		}
+		thread, err := createThread(ctx, client, payload)
+		if err != nil {
+			return bus.ActionResult{}, err
+		}
+		return bus.ActionResult{ChannelID: thread.ID(), MessageID: payload.MessageID}, nil
 	case bus.LogEvent:
 		logEventToConsole(ctx, logger, payload)
+		sink.publish(ctx, payload)
+		return bus.ActionResult{}, nil
 	default:
 		logger.Warn("unknown discord action", slog.String("type", fmt.Sprintf("%T", action)))
+		return bus.ActionResult{}, nil
+	}
+}
+
+func createThread(ctx context.Context, client bot.Client, payload bus.CreateThread) (*discord.GuildThread, error) {
+	name := truncateThreadName(strings.TrimSpace(payload.Name))
+	archive := discord.AutoArchiveDuration(payload.AutoArchive)
+	opts := requestOpts(ctx, payload.Reason)
+
+	if payload.MessageID != 0 {
+		return client.Rest().CreateThreadFromMessage(payload.ChannelID, payload.MessageID, discord.ThreadCreateFromMessage{
+			Name:                name,
+			AutoArchiveDuration: archive,
+		}, opts...)
+	}
+
+	var create discord.ThreadCreate = discord.GuildPublicThreadCreate{Name: name, AutoArchiveDuration: archive}
+	if payload.Private {
+		create = discord.GuildPrivateThreadCreate{Name: name, AutoArchiveDuration: archive}
+	}
+	return client.Rest().CreateThread(payload.ChannelID, create, opts...)
+}
+
+// truncateThreadName keeps names within Discord's 100 character limit.
+func truncateThreadName(name string) string {
+	runes := []rune(name)
+	if len(runes) > 100 {
+		return string(runes[:100])
+	}
+	return name
+}
+
+func messageResult(message *discord.Message, err error) (bus.ActionResult, error) {
+	if err != nil || message == nil {
+		return bus.ActionResult{}, err
+	}
+	return bus.ActionResult{ChannelID: message.ChannelID, MessageID: message.ID}, nil
+}
+
+func requestOpts(ctx context.Context, reason string) []rest.RequestOpt {
+	opts := []rest.RequestOpt{rest.WithCtx(ctx)}
+	if reason = strings.TrimSpace(reason); reason != "" {
+		opts = append(opts, rest.WithReason(reason))
 	}
+	return opts
 }
 
 func logEventToConsole(ctx context.Context, logger *slog.Logger, event bus.LogEvent) {
@@ -0,0 +1,331 @@
+package actions
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"antartica-bot/internal/bus"
+	"antartica-bot/internal/discord/embeds"
+	"antartica-bot/internal/settings"
+
+	"github.com/disgoorg/disgo/bot"
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/rest"
+	"github.com/disgoorg/snowflake/v2"
+)
+
+const (
+	// logBatchWindow is how long the sink waits for more events before posting a batch.
+	logBatchWindow = 2 * time.Second
+	// logMaxEmbedsPerMessage is Discord's limit on embeds in a single message.
+	logMaxEmbedsPerMessage = 10
+	// logMaxPendingPerChannel caps queued embeds per channel; anything beyond is summarised instead of posted.
+	logMaxPendingPerChannel = 50
+
+	logMaxTitleLength       = 256
+	logMaxDescriptionLength = 4096
+	logMaxFieldValueLength  = 1024
+	// logMaxMessageLength is Discord's limit on the combined text of all embeds in a message.
+	logMaxMessageLength = 6000
+)
+
+// logSink renders LogEvents as embeds and posts them to the guild's configured log channels.
+// Events no route matches go to the guild's log.default_channel setting, if any.
+// Events are batched per channel so a burst becomes a few messages rather than one per event. Batches
+// are retried like actions, but a batch that fails for good is only logged, never reported back to the
+// log channels.
+type logSink struct {
+	// ctx stops batches posted when their window ends, along with the worker.
+	ctx      context.Context
+	client   bot.Client
+	routes   bus.LogRouteStore
+	settings bus.GuildSettingsStore
+	logger   *slog.Logger
+	window   time.Duration
+
+	mu      sync.Mutex
+	pending map[snowflake.ID][]discord.Embed
+	dropped map[snowflake.ID]int
+}
+
+func newLogSink(ctx context.Context, client bot.Client, routes bus.LogRouteStore, guildSettings bus.GuildSettingsStore, logger *slog.Logger) *logSink {
+	return &logSink{
+		ctx:      ctx,
+		client:   client,
+		routes:   routes,
+		settings: guildSettings,
+		logger:   logger,
+		window:   logBatchWindow,
+		pending:  make(map[snowflake.ID][]discord.Embed),
+		dropped:  make(map[snowflake.ID]int),
+	}
+}
+
+func (s *logSink) publish(ctx context.Context, event bus.LogEvent) {
+	if s == nil || s.routes == nil || s.client == nil || event.GuildID == 0 {
+		return
+	}
+
+	routes, err := s.routes.ListLogRoutes(ctx, event.GuildID)
+	if err != nil {
+		s.logger.Warn("log route lookup failed", slog.Any("err", err), slog.String("guild_id", event.GuildID.String()))
+		return
+	}
+
+	channels := matchLogRoutes(routes, event)
+	if len(channels) == 0 {
+		channels = s.defaultChannel(ctx, event)
+	}
+	if len(channels) == 0 {
+		return
+	}
+
+	embed := buildLogEmbed(event)
+	for _, channelID := range channels {
+		s.enqueue(channelID, embed)
+	}
+}
+
+func (s *logSink) enqueue(channelID snowflake.ID, embed discord.Embed) {
+	s.mu.Lock()
+	queue := s.pending[channelID]
+	if len(queue) >= logMaxPendingPerChannel {
+		s.dropped[channelID]++
+		s.mu.Unlock()
+		return
+	}
+	queue = append(queue, embed)
+	s.pending[channelID] = queue
+	first := len(queue) == 1
+	s.mu.Unlock()
+
+	if first {
+		time.AfterFunc(s.window, func() {
+			s.flush(s.ctx, channelID)
+		})
+	}
+}
+
+func (s *logSink) flush(ctx context.Context, channelID snowflake.ID) {
+	s.mu.Lock()
+	queue := s.pending[channelID]
+	dropped := s.dropped[channelID]
+	delete(s.pending, channelID)
+	delete(s.dropped, channelID)
+	s.mu.Unlock()
+
+	if dropped > 0 {
+		queue = append(queue, embeds.BuildEmbed(embeds.EmbedTemplate{
+			Tone:        embeds.EmbedWarn,
+			Title:       "Log events suppressed",
+			Description: fmt.Sprintf("%d more log events were dropped because too many arrived at once.", dropped),
+		}))
+	}
+
+	for _, batch := range batchLogEmbeds(queue) {
+		if err := s.post(ctx, channelID, batch); err != nil {
+			s.logger.Error(
+				"discord log message failed",
+				slog.Any("err", err),
+				slog.String("channel_id", channelID.String()),
+				slog.Int("embeds", len(batch)),
+			)
+		}
+	}
+}
+
+// post sends one batch, retrying rate limits and transient failures with the worker's backoff.
+func (s *logSink) post(ctx context.Context, channelID snowflake.ID, batch []discord.Embed) error {
+	for attempt := 1; ; attempt++ {
+		_, err := s.client.Rest().CreateMessage(channelID, discord.MessageCreate{Embeds: batch}, rest.WithCtx(ctx))
+		if err == nil || ctx.Err() != nil {
+			return err
+		}
+
+		kind, retryAfter := classifyActionError(err)
+		if kind == failurePermanent || kind == failureRejected || attempt >= actionMaxAttempts {
+			return err
+		}
+		wait := actionBackoff(attempt)
+		if kind == failureRateLimited && retryAfter > 0 {
+			wait = retryAfter
+		}
+
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// flushAll posts every pending batch now instead of waiting for its window. It reports whether
+// there was anything to post; timers that fire later find nothing left.
+func (s *logSink) flushAll(ctx context.Context) bool {
+	if s == nil {
+		return false
+	}
+
+	s.mu.Lock()
+	channels := make([]snowflake.ID, 0, len(s.pending))
+	for channelID := range s.pending {
+		channels = append(channels, channelID)
+	}
+	s.mu.Unlock()
+
+	for _, channelID := range channels {
+		s.flush(ctx, channelID)
+	}
+	return len(channels) > 0
+}
+
+// pendingEmbeds counts the embeds waiting for their batch window.
+func (s *logSink) pendingEmbeds() int {
+	if s == nil {
+		return 0
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	count := 0
+	for _, queue := range s.pending {
+		count += len(queue)
+	}
+	return count
+}
+
+// batchLogEmbeds splits embeds into messages that respect Discord's per-message embed count and text limits.
+func batchLogEmbeds(queue []discord.Embed) [][]discord.Embed {
+	var batches [][]discord.Embed
+	var batch []discord.Embed
+	total := 0
+	for _, embed := range queue {
+		length := logEmbedLength(embed)
+		if len(batch) > 0 && (len(batch) >= logMaxEmbedsPerMessage || total+length > logMaxMessageLength) {
+			batches = append(batches, batch)
+			batch = nil
+			total = 0
+		}
+		batch = append(batch, embed)
+		total += length
+	}
+	if len(batch) > 0 {
+		batches = append(batches, batch)
+	}
+	return batches
+}
+
+func logEmbedLength(embed discord.Embed) int {
+	length := utf8.RuneCountInString(embed.Title) + utf8.RuneCountInString(embed.Description)
+	for _, field := range embed.Fields {
+		length += utf8.RuneCountInString(field.Name) + utf8.RuneCountInString(field.Value)
+	}
+	if embed.Footer != nil {
+		length += utf8.RuneCountInString(embed.Footer.Text)
+	}
+	return length
+}
+
+// defaultChannel returns the guild's fallback log channel for events at info and above. A channel that
+// isn't in the event's guild is ignored, so one guild's logs never reach another.
+func (s *logSink) defaultChannel(ctx context.Context, event bus.LogEvent) []snowflake.ID {
+	if event.Level.Severity() < bus.LogInfo.Severity() {
+		return nil
+	}
+	guild, err := settings.Load(ctx, s.settings, event.GuildID)
+	if err != nil {
+		s.logger.Warn("guild settings lookup failed", slog.Any("err", err), slog.String("guild_id", event.GuildID.String()))
+		return nil
+	}
+	channelID, ok := guild.Channel(settings.LogDefaultChannel)
+	if !ok {
+		return nil
+	}
+	if channel, ok := s.client.Caches().Channel(channelID); !ok || channel.GuildID() != event.GuildID {
+		s.logger.Warn("default log channel is not in the guild", slog.String("guild_id", event.GuildID.String()), slog.String("channel_id", channelID.String()))
+		return nil
+	}
+	return []snowflake.ID{channelID}
+}
+
+// matchLogRoutes returns the distinct channels whose route accepts the event's category and level.
+func matchLogRoutes(routes []bus.LogRoute, event bus.LogEvent) []snowflake.ID {
+	category := strings.ToLower(strings.TrimSpace(event.Category))
+	severity := event.Level.Severity()
+
+	seen := make(map[snowflake.ID]struct{}, len(routes))
+	channels := make([]snowflake.ID, 0, len(routes))
+	for _, route := range routes {
+		routeCategory := strings.ToLower(strings.TrimSpace(route.Category))
+		if routeCategory != "" && routeCategory != category {
+			continue
+		}
+		if severity < route.MinLevel.Severity() {
+			continue
+		}
+		if _, ok := seen[route.ChannelID]; ok {
+			continue
+		}
+		seen[route.ChannelID] = struct{}{}
+		channels = append(channels, route.ChannelID)
+	}
+	return channels
+}
+
+func buildLogEmbed(event bus.LogEvent) discord.Embed {
+	title := strings.TrimSpace(event.Title)
+	if title == "" {
+		title = defaultLogMessage(event)
+	}
+
+	fields := make([]discord.EmbedField, 0, len(event.Fields))
+	for _, field := range event.Fields {
+		name := strings.TrimSpace(field.Name)
+		value := strings.TrimSpace(field.Value)
+		if name == "" || value == "" {
+			continue
+		}
+		inline := field.Inline
+		fields = append(fields, discord.EmbedField{Name: name, Value: truncateLogText(value, logMaxFieldValueLength), Inline: &inline})
+	}
+
+	timestamp := event.Timestamp
+	if timestamp.IsZero() {
+		timestamp = time.Now()
+	}
+
+	return embeds.BuildEmbed(embeds.EmbedTemplate{
+		Tone:        logLevelTone(event.Level),
+		Title:       truncateLogText(title, logMaxTitleLength),
+		Description: truncateLogText(event.Description, logMaxDescriptionLength),
+		Fields:      fields,
+		Footer:      strings.TrimSpace(event.Category),
+		Timestamp:   &timestamp,
+	})
+}
+
+func logLevelTone(level bus.LogLevel) embeds.EmbedTone {
+	switch level {
+	case bus.LogDebug:
+		return embeds.EmbedDebug
+	case bus.LogWarn:
+		return embeds.EmbedWarn
+	case bus.LogError:
+		return embeds.EmbedError
+	default:
+		return embeds.EmbedInfo
+	}
+}
+
+func truncateLogText(value string, limit int) string {
+	runes := []rune(value)
+	if len(runes) <= limit {
+		return value
+	}
+	return string(runes[:limit-1]) + "…"
+}
@@ -0,0 +1,517 @@
+package actions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"antartica-bot/internal/bus"
+	"antartica-bot/internal/metrics"
+
+	"github.com/disgoorg/disgo/bot"
+	"github.com/disgoorg/disgo/rest"
+	"github.com/disgoorg/snowflake/v2"
+)
+
+const (
+	defaultActionWorkers = 4
+	// actionMaxAttempts includes the first try.
+	actionMaxAttempts = 5
+	actionBaseBackoff = time.Second
+	actionMaxBackoff  = 30 * time.Second
+
+	actionLogCategory = "actions"
+
+	// maxLanePending and maxPoolPending cap the actions waiting in memory. Once either is reached dispatch
+	// blocks, so the bus queue fills up and its overflow policy applies.
+	maxLanePending = 100
+	maxPoolPending = 1000
+
+	// outboxPruneInterval is how often finished outbox entries are checked against their retention.
+	outboxPruneInterval = time.Hour
+)
+
+type failureKind int
+
+const (
+	// failureTransient covers network errors and 5xx responses.
+	failureTransient failureKind = iota
+	failureRateLimited
+	// failurePermanent covers 403 and 404: the bot lost access or the target is gone, so retrying can't help.
+	failurePermanent
+	// failureRejected covers other 4xx responses, which mean the action itself is invalid.
+	failureRejected
+)
+
+// workerPool runs actions on a bounded number of workers. Each lane runs its actions in order.
+type workerPool struct {
+	client bot.Client
+	events *bus.Bus
+	logger *slog.Logger
+	sink   *logSink
+	outbox bus.ActionOutbox
+	slots  chan struct{}
+
+	mu      sync.Mutex
+	lanes   map[string]*lane
+	pending int
+	// space is signalled whenever a queued action starts running or ctx ends.
+	space *sync.Cond
+}
+
+type lane struct {
+	pending []bus.DiscordAction
+	running bus.DiscordAction
+}
+
+func newWorkerPool(client bot.Client, eventBus *bus.Bus, logger *slog.Logger, sink *logSink, workers int) *workerPool {
+	if workers <= 0 {
+		workers = defaultActionWorkers
+	}
+	pool := &workerPool{
+		client: client,
+		events: eventBus,
+		logger: logger,
+		sink:   sink,
+		outbox: eventBus.Outbox,
+		slots:  make(chan struct{}, workers),
+		lanes:  make(map[string]*lane),
+	}
+	pool.space = sync.NewCond(&pool.mu)
+	return pool
+}
+
+// dispatch queues an action on its lane, starting the lane if it was idle.
+// An edit replaces a queued edit of the same message rather than running after it.
+// While the lane or the pool is full it blocks; it returns false if ctx ends first.
+func (p *workerPool) dispatch(ctx context.Context, action bus.DiscordAction) bool {
+	key := actionLane(action)
+	stop := context.AfterFunc(ctx, func() {
+		p.mu.Lock()
+		p.space.Broadcast()
+		p.mu.Unlock()
+	})
+	defer stop()
+
+	p.mu.Lock()
+	var (
+		current  *lane
+		active   bool
+		replaced bus.DiscordAction
+	)
+	for {
+		if ctx.Err() != nil {
+			p.mu.Unlock()
+			return false
+		}
+		current, active = p.lanes[key]
+		if !active {
+			current = &lane{}
+		}
+		var ok bool
+		if replaced, ok = current.supersede(action); ok {
+			break
+		}
+		if len(current.pending) < maxLanePending && p.pending < maxPoolPending {
+			current.pending = append(current.pending, action)
+			p.pending++
+			break
+		}
+		p.space.Wait()
+	}
+	if !active {
+		p.lanes[key] = current
+	}
+	p.mu.Unlock()
+
+	if replaced != nil {
+		_, inner := bus.Unwrap(replaced)
+		metrics.ActionFinished(actionType(inner), metrics.ActionSuperseded)
+		p.finish(ctx, replaced, bus.ActionResult{}, nil, true)
+	}
+
+	if !active {
+		go p.runLane(ctx, key, current)
+	}
+	return true
+}
+
+func (p *workerPool) runLane(ctx context.Context, key string, current *lane) {
+	for {
+		p.mu.Lock()
+		if len(current.pending) == 0 || ctx.Err() != nil {
+			p.pending -= len(current.pending)
+			delete(p.lanes, key)
+			p.space.Broadcast()
+			p.mu.Unlock()
+			return
+		}
+		action := current.pending[0]
+		current.pending = current.pending[1:]
+		current.running = action
+		p.pending--
+		p.space.Broadcast()
+		p.mu.Unlock()
+
+		p.run(ctx, key, action)
+
+		p.mu.Lock()
+		current.running = nil
+		p.mu.Unlock()
+	}
+}
+
+// idle reports whether no lane has work queued or running.
+func (p *workerPool) idle() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.lanes) == 0
+}
+
+// undelivered lists the running and queued actions of every lane.
+func (p *workerPool) undelivered() []bus.DiscordAction {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var actions []bus.DiscordAction
+	for _, current := range p.lanes {
+		if current.running != nil {
+			actions = append(actions, current.running)
+		}
+		actions = append(actions, current.pending...)
+	}
+	return actions
+}
+
+// run executes an action, retrying transient failures and rate limits with backoff.
+// Outboxed actions are claimed first, so one replayed from both the outbox and a spill file runs once.
+// If ctx ends mid-flight the entry is left in progress and replayed on the next start.
+func (p *workerPool) run(ctx context.Context, key string, queued bus.DiscordAction) {
+	id, action := bus.Unwrap(queued)
+	if id != "" && p.outbox != nil {
+		claimed, err := p.outbox.Claim(ctx, id)
+		if err != nil {
+			p.logger.Warn("outbox claim failed", slog.String("outbox_id", id), slog.Any("err", err))
+		} else if !claimed {
+			return
+		}
+	}
+
+	for attempt := 1; ; attempt++ {
+		result, err := p.execute(ctx, action)
+		if ctx.Err() != nil {
+			return
+		}
+		if err == nil {
+			metrics.ActionFinished(actionType(action), metrics.ActionSuccess)
+			p.finish(ctx, queued, result, nil, false)
+			return
+		}
+
+		kind, retryAfter := classifyActionError(err)
+		switch kind {
+		case failurePermanent:
+			metrics.ActionFinished(actionType(action), metrics.ActionAbandoned)
+			p.notifyFailure(ctx, action, err)
+			p.finish(ctx, queued, bus.ActionResult{}, err, false)
+			return
+		case failureRejected:
+			metrics.ActionFinished(actionType(action), metrics.ActionRejected)
+			p.logFailure(action, err, attempt)
+			p.finish(ctx, queued, bus.ActionResult{}, err, false)
+			return
+		}
+		if attempt >= actionMaxAttempts {
+			metrics.ActionFinished(actionType(action), metrics.ActionFailed)
+			p.logFailure(action, err, attempt)
+			p.notifyFailure(ctx, action, err)
+			p.finish(ctx, queued, bus.ActionResult{}, err, false)
+			return
+		}
+		metrics.ActionFinished(actionType(action), metrics.ActionRetried)
+
+		wait := actionBackoff(attempt)
+		if kind == failureRateLimited && retryAfter > 0 {
+			wait = retryAfter
+		}
+		p.logger.Debug(
+			"discord action retrying",
+			slog.String("type", fmt.Sprintf("%T", action)),
+			slog.Int("attempt", attempt),
+			slog.Duration("wait", wait),
+			slog.Any("err", err),
+		)
+
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return
+		}
+
+		if p.superseded(key, action) {
+			metrics.ActionFinished(actionType(action), metrics.ActionSuperseded)
+			p.finish(ctx, queued, bus.ActionResult{}, nil, true)
+			return
+		}
+	}
+}
+
+// finish records the outcome of an action in the outbox and reports it to the caller when it asked through its Reply.
+func (p *workerPool) finish(ctx context.Context, queued bus.DiscordAction, result bus.ActionResult, failure error, superseded bool) {
+	id, action := bus.Unwrap(queued)
+	if id != "" && p.outbox != nil {
+		var err error
+		switch {
+		case failure != nil:
+			err = p.outbox.Fail(ctx, id, failure.Error())
+		case superseded:
+			err = p.outbox.Complete(ctx, id, "superseded by a newer edit")
+		default:
+			err = p.outbox.Complete(ctx, id, "")
+		}
+		if err != nil {
+			p.logger.Warn("outbox update failed", slog.String("outbox_id", id), slog.Any("err", err))
+		}
+	}
+
+	reply := bus.ReplyOf(action)
+	if reply.Ref == "" && reply.C == nil {
+		return
+	}
+
+	channelID, messageID := actionMessage(action)
+	if result.ChannelID == 0 {
+		result.ChannelID = channelID
+	}
+	if result.MessageID == 0 {
+		result.MessageID = messageID
+	}
+	result.Ref = reply.Ref
+	result.Superseded = superseded
+	if failure != nil {
+		result.Error = failure.Error()
+	}
+
+	if reply.C != nil {
+		select {
+		case reply.C <- result:
+		default:
+			p.logger.Warn("discord action result dropped", slog.String("type", fmt.Sprintf("%T", action)), slog.String("ref", reply.Ref))
+		}
+	}
+	if reply.Ref != "" {
+		if err := p.events.PublishEvent(ctx, result); err != nil {
+			p.logger.Warn("discord action result not published", slog.String("type", fmt.Sprintf("%T", action)), slog.String("ref", reply.Ref), slog.Any("err", err))
+		}
+	}
+}
+
+// execute holds a worker slot only while the REST call runs, so backoff doesn't starve other lanes.
+func (p *workerPool) execute(ctx context.Context, action bus.DiscordAction) (bus.ActionResult, error) {
+	select {
+	case p.slots <- struct{}{}:
+	case <-ctx.Done():
+		return bus.ActionResult{}, ctx.Err()
+	}
+	defer func() { <-p.slots }()
+
+	return executeAction(ctx, p.client, p.logger, p.sink, action)
+}
+
+// superseded reports whether a newer edit of the same message is already queued.
+func (p *workerPool) superseded(key string, action bus.DiscordAction) bool {
+	edit, ok := action.(bus.EditMessage)
+	if !ok {
+		return false
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	current, ok := p.lanes[key]
+	if !ok {
+		return false
+	}
+	for _, pending := range current.pending {
+		_, inner := bus.Unwrap(pending)
+		if next, ok := inner.(bus.EditMessage); ok && next.MessageID == edit.MessageID {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *workerPool) logFailure(action bus.DiscordAction, err error, attempts int) {
+	p.logger.Error(
+		"discord action failed",
+		slog.String("type", fmt.Sprintf("%T", action)),
+		slog.Int("attempts", attempts),
+		slog.Any("err", err),
+	)
+}
+
+// notifyFailure reports an action that will never succeed to the guild's log channels.
+func (p *workerPool) notifyFailure(ctx context.Context, action bus.DiscordAction, err error) {
+	guildID, channelID := p.actionTarget(action)
+	p.logger.Error(
+		"discord action abandoned",
+		slog.String("type", fmt.Sprintf("%T", action)),
+		slog.String("guild_id", guildID.String()),
+		slog.String("channel_id", channelID.String()),
+		slog.Any("err", err),
+	)
+	if guildID == 0 {
+		return
+	}
+
+	fields := []bus.LogField{
+		{Name: "Action", Value: fmt.Sprintf("%T", action), Inline: true},
+	}
+	if channelID != 0 {
+		fields = append(fields, bus.LogField{Name: "Channel", Value: fmt.Sprintf("<#%s>", channelID), Inline: true})
+	}
+	var restErr rest.Error
+	if errors.As(err, &restErr) && restErr.Response != nil {
+		fields = append(fields, bus.LogField{Name: "Status", Value: restErr.Response.Status, Inline: true})
+	}
+
+	p.sink.publish(ctx, bus.LogEvent{
+		GuildID:     guildID,
+		Category:    actionLogCategory,
+		Level:       bus.LogWarn,
+		Title:       "Discord action abandoned",
+		Description: "The bot gave up on this action. Check that it can still see and post in the channel and that the target still exists.",
+		Fields:      fields,
+		Timestamp:   time.Now(),
+	})
+}
+
+func (p *workerPool) actionTarget(action bus.DiscordAction) (snowflake.ID, snowflake.ID) {
+	switch payload := action.(type) {
+	case bus.AddMemberRole:
+		return payload.GuildID, 0
+	case bus.RemoveMemberRole:
+		return payload.GuildID, 0
+	case bus.LogEvent:
+		return payload.GuildID, 0
+	}
+
+	channelID, _ := actionMessage(action)
+	if channelID != 0 {
+		if channel, ok := p.client.Caches().Channel(channelID); ok {
+			return channel.GuildID(), channelID
+		}
+	}
+	return 0, channelID
+}
+
+// supersede replaces a queued edit of the same message with action, returning the replaced one.
+func (l *lane) supersede(action bus.DiscordAction) (bus.DiscordAction, bool) {
+	_, inner := bus.Unwrap(action)
+	edit, ok := inner.(bus.EditMessage)
+	if !ok {
+		return nil, false
+	}
+	for index, pending := range l.pending {
+		_, queued := bus.Unwrap(pending)
+		if previous, ok := queued.(bus.EditMessage); ok && previous.MessageID == edit.MessageID {
+			l.pending[index] = action
+			return pending, true
+		}
+	}
+	return nil, false
+}
+
+// actionType names an action for metrics, e.g. SendMessage.
+func actionType(action bus.DiscordAction) string {
+	return strings.TrimPrefix(fmt.Sprintf("%T", action), "bus.")
+}
+
+// actionMessage returns the channel and message an action targets, used when the result has none.
+func actionMessage(action bus.DiscordAction) (snowflake.ID, snowflake.ID) {
+	switch payload := action.(type) {
+	case bus.SendMessage:
+		return payload.ChannelID, 0
+	case bus.SendEmbed:
+		return payload.ChannelID, 0
+	case bus.EditMessage:
+		return payload.ChannelID, payload.MessageID
+	case bus.DeleteMessage:
+		return payload.ChannelID, payload.MessageID
+	case bus.AddReaction:
+		return payload.ChannelID, payload.MessageID
+	case bus.CreateThread:
+		return payload.ChannelID, payload.MessageID
+	default:
+		return 0, 0
+	}
+}
+
+// actionLane groups actions that must not run concurrently.
+func actionLane(action bus.DiscordAction) string {
+	_, action = bus.Unwrap(action)
+	switch payload := action.(type) {
+	case bus.AddMemberRole:
+		return "guild:" + payload.GuildID.String()
+	case bus.RemoveMemberRole:
+		return "guild:" + payload.GuildID.String()
+	case bus.SendDM:
+		return "user:" + payload.UserID.String()
+	case bus.LogEvent:
+		return "log"
+	}
+
+	if channelID, _ := actionMessage(action); channelID != 0 {
+		return "channel:" + channelID.String()
+	}
+	return "default"
+}
+
+func classifyActionError(err error) (failureKind, time.Duration) {
+	var restErr rest.Error
+	if !errors.As(err, &restErr) || restErr.Response == nil {
+		return failureTransient, 0
+	}
+
+	status := restErr.Response.StatusCode
+	switch {
+	case status == http.StatusTooManyRequests:
+		return failureRateLimited, parseRetryAfter(restErr.Response.Header.Get("Retry-After"))
+	case status == http.StatusForbidden || status == http.StatusNotFound:
+		return failurePermanent, 0
+	case status >= http.StatusInternalServerError:
+		return failureTransient, 0
+	default:
+		return failureRejected, 0
+	}
+}
+
+func isStatus(err error, status int) bool {
+	var restErr rest.Error
+	return errors.As(err, &restErr) && restErr.Response != nil && restErr.Response.StatusCode == status
+}
+
+func parseRetryAfter(raw string) time.Duration {
+	seconds, err := strconv.ParseFloat(raw, 64)
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// actionBackoff doubles per attempt with jitter, capped at actionMaxBackoff.
+func actionBackoff(attempt int) time.Duration {
+	wait := actionBaseBackoff << (attempt - 1)
+	if wait > actionMaxBackoff || wait <= 0 {
+		wait = actionMaxBackoff
+	}
+	jitter := time.Duration(rand.Int64N(int64(wait) / 2))
+	return wait/2 + jitter
+}
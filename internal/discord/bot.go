@@ -4,20 +4,33 @@ import (
 	"context"
 	"errors"
 	"log/slog"
+	"net/http"
 	"strings"
+	"time"
 
 	"antartica-bot/internal/bus"
 	"antartica-bot/internal/discord/handlers"
+	"antartica-bot/internal/metrics"
 
 	"github.com/disgoorg/disgo"
 	"github.com/disgoorg/disgo/bot"
 	"github.com/disgoorg/disgo/events"
 	"github.com/disgoorg/disgo/gateway"
+	"github.com/disgoorg/disgo/rest"
 )
 
 type Config struct {
-	Token   string
+	Token string
+	// Intents are the configured gateway intents. Zero uses DefaultConfig's; intents needed by enabled features are added.
 	Intents gateway.Intents
+	// MembersIntent adds the privileged Server Members intent, needed for member join/leave/update events.
+	MembersIntent bool
+	// MessageContentIntent adds the privileged Message Content intent, needed to see message text.
+	MessageContentIntent bool
+	// MessageLog caches up to MessageCacheSize recent messages and logs their edits and deletes.
+	MessageLog       bool
+	MessageCacheSize int
+	Presence         Presence
 }
 
 func DefaultConfig() Config {
@@ -29,23 +42,37 @@ func DefaultConfig() Config {
 type Bot struct {
 	client  bot.Client
 	handler *handlers.Handler
+	intents gateway.Intents
+	gateway *gatewayState
 }
 
-func New(cfg Config, eventBus *bus.Bus, logger *slog.Logger, roleToggleStore bus.RoleToggleStore, reactionTrackStore bus.ReactionTrackStore, staticMessageStore bus.StaticMessageStore) (*Bot, error) {
+func New(cfg Config, eventBus *bus.Bus, logger *slog.Logger, roleToggleStore bus.RoleToggleStore, reactionTrackStore bus.ReactionTrackStore, staticMessageStore bus.StaticMessageStore, autoRoleStore bus.AutoRoleStore, logRouteStore bus.LogRouteStore, auditLogStore bus.AuditLogStore, guildSettingsStore bus.GuildSettingsStore, commandPermissionStore bus.CommandPermissionStore) (*Bot, error) {
 	if strings.TrimSpace(cfg.Token) == "" {
 		return nil, errors.New("discord token is required")
 	}
 	if logger == nil {
 		logger = slog.Default()
 	}
-	if cfg.Intents == 0 {
-		cfg.Intents = DefaultConfig().Intents
+	cfg.Intents = resolveIntents(cfg)
+
+	memberChunking := bot.MemberChunkingFilterNone
+	if cfg.Intents.Has(gateway.IntentGuildMembers) {
+		// Role member counts are read from the member cache, so it has to be complete.
+		memberChunking = bot.MemberChunkingFilterAll
 	}
 
 	var handler *handlers.Handler
+	state := &gatewayState{}
 	client, err := disgo.New(cfg.Token,
 		bot.WithLogger(logger),
-		bot.WithGatewayConfigOpts(gateway.WithIntents(cfg.Intents)),
+		// Raw events are only needed to see bulk deletes before disgo splits them up.
+		bot.WithGatewayConfigOpts(gateway.WithIntents(cfg.Intents), gateway.WithEnableRawEvents(cfg.MessageLog), gateway.WithPresenceOpts(presenceOpt(cfg.Presence))),
+		bot.WithMemberChunkingFilter(memberChunking),
+		bot.WithRestClientConfigOpts(rest.WithHTTPClient(&http.Client{
+			Timeout:   20 * time.Second,
+			Transport: metrics.Transport(nil),
+		})),
+		bot.WithEventListenerFunc(state.onEvent),
 		bot.WithEventListenerFunc(func(event *events.GuildMessageReactionAdd) {
 			if handler != nil {
 				handler.OnGuildMessageReactionAdd(event)
@@ -66,11 +93,56 @@ func New(cfg Config, eventBus *bus.Bus, logger *slog.Logger, roleToggleStore bus
 				handler.OnGuildMessageReactionRemoveAll(event)
 			}
 		}),
+		bot.WithEventListenerFunc(func(event *events.GuildMessageCreate) {
+			if handler != nil {
+				handler.OnGuildMessageCreate(event)
+			}
+		}),
+		bot.WithEventListenerFunc(func(event *events.GuildMessageUpdate) {
+			if handler != nil {
+				handler.OnGuildMessageUpdate(event)
+			}
+		}),
+		bot.WithEventListenerFunc(func(event *events.Raw) {
+			if handler != nil {
+				handler.OnRaw(event)
+			}
+		}),
 		bot.WithEventListenerFunc(func(event *events.GuildMessageDelete) {
 			if handler != nil {
 				handler.OnGuildMessageDelete(event)
 			}
 		}),
+		bot.WithEventListenerFunc(func(event *events.GuildMemberJoin) {
+			if handler != nil {
+				handler.OnGuildMemberJoin(event)
+			}
+		}),
+		bot.WithEventListenerFunc(func(event *events.GuildMemberUpdate) {
+			if handler != nil {
+				handler.OnGuildMemberUpdate(event)
+			}
+		}),
+		bot.WithEventListenerFunc(func(event *events.GuildMemberLeave) {
+			if handler != nil {
+				handler.OnGuildMemberLeave(event)
+			}
+		}),
+		bot.WithEventListenerFunc(func(event *events.GuildAuditLogEntryCreate) {
+			if handler != nil {
+				handler.OnGuildAuditLogEntryCreate(event)
+			}
+		}),
+		bot.WithEventListenerFunc(func(event *events.RoleUpdate) {
+			if handler != nil {
+				handler.OnRoleUpdate(event)
+			}
+		}),
+		bot.WithEventListenerFunc(func(event *events.RoleDelete) {
+			if handler != nil {
+				handler.OnRoleDelete(event)
+			}
+		}),
 		bot.WithEventListenerFunc(func(event *events.InteractionCreate) {
 			if handler != nil {
 				handler.OnInteractionCreate(event)
@@ -81,16 +153,26 @@ func New(cfg Config, eventBus *bus.Bus, logger *slog.Logger, roleToggleStore bus
 				handler.OnApplicationCommand(event)
 			}
 		}),
+		bot.WithEventListenerFunc(func(event *events.AutocompleteInteractionCreate) {
+			if handler != nil {
+				handler.OnAutocomplete(event)
+			}
+		}),
 	)
 	if err != nil {
 		return nil, err
 	}
 
-	handler = handlers.New(client, eventBus, logger, roleToggleStore, reactionTrackStore, staticMessageStore)
+	handler = handlers.New(client, eventBus, logger, roleToggleStore, reactionTrackStore, staticMessageStore, autoRoleStore, logRouteStore, auditLogStore, guildSettingsStore, commandPermissionStore)
+	if cfg.MessageLog {
+		handler.EnableMessageLog(cfg.MessageCacheSize)
+	}
 
 	return &Bot{
 		client:  client,
 		handler: handler,
+		intents: cfg.Intents,
+		gateway: state,
 	}, nil
 }
 
@@ -105,3 +187,14 @@ func (b *Bot) Close(ctx context.Context) {
 func (b *Bot) Client() bot.Client {
 	return b.client
 }
+
+// ErrMessageLogNeedsRestart is returned when the message log is switched on but wasn't enabled at startup.
+var ErrMessageLogNeedsRestart = errors.New("message log was disabled at startup; enabling it needs a restart")
+
+// SetMessageLog pauses or resumes the message log.
+func (b *Bot) SetMessageLog(enabled bool) error {
+	if !b.handler.SetMessageLogActive(enabled) {
+		return ErrMessageLogNeedsRestart
+	}
+	return nil
+}
@@ -7,8 +7,32 @@ import (
 
 const ReactionCommandName = "reaction"
 
+// ReactionHandlers implements /reaction.
+type ReactionHandlers interface {
+	ReactionTrackAdd(*Context) error
+	ReactionTrackRemove(*Context) error
+	ReactionTrackList(*Context) error
+	LeaderboardCreate(*Context) error
+	LeaderboardRemove(*Context) error
+	LeaderboardList(*Context) error
+}
+
 func init() {
-	Register(ReactionCommand)
+	Register(Command{
+		Create:    ReactionCommand,
+		GuildOnly: true,
+		Bind: func(target any) Routes {
+			h := target.(ReactionHandlers)
+			return Routes{
+				"/reaction/add":                Require(NeedsReactionTracks, h.ReactionTrackAdd),
+				"/reaction/remove":             Require(NeedsReactionTracks, h.ReactionTrackRemove),
+				"/reaction/list":               Require(NeedsReactionTracks, h.ReactionTrackList),
+				"/reaction/leaderboard/create": Require(NeedsStaticMessages, h.LeaderboardCreate),
+				"/reaction/leaderboard/remove": Require(NeedsStaticMessages, h.LeaderboardRemove),
+				"/reaction/leaderboard/list":   Require(NeedsStaticMessages, h.LeaderboardList),
+			}
+		},
+	})
 }
 
 func ReactionCommand() discord.ApplicationCommandCreate {
@@ -0,0 +1,69 @@
+package commands
+
+import (
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/json"
+)
+
+const AuditCommandName = "audit"
+
+var (
+	auditMinLimit = 1
+	auditMaxLimit = 25
+)
+
+// AuditHandlers implements /audit.
+type AuditHandlers interface {
+	AuditList(*Context) error
+}
+
+func init() {
+	Register(Command{
+		Create:    AuditCommand,
+		GuildOnly: true,
+		Bind: func(target any) Routes {
+			h := target.(AuditHandlers)
+			return Routes{
+				"/audit": Require(NeedsAuditLog, h.AuditList),
+			}
+		},
+	})
+}
+
+func AuditCommand() discord.ApplicationCommandCreate {
+	manageGuild := json.NewNullable(discord.PermissionManageGuild)
+	return discord.SlashCommandCreate{
+		Name:                     AuditCommandName,
+		Description:              "Show recent admin command activity",
+		Contexts:                 []discord.InteractionContextType{discord.InteractionContextTypeGuild},
+		DefaultMemberPermissions: &manageGuild,
+		Options: []discord.ApplicationCommandOption{
+			discord.ApplicationCommandOptionUser{
+				Name:        "user",
+				Description: "Only show commands run by this member",
+			},
+			discord.ApplicationCommandOptionString{
+				Name:        "command",
+				Description: "Only show commands starting with this path, e.g. /role or /role/auto",
+			},
+			discord.ApplicationCommandOptionString{
+				Name:        "outcome",
+				Description: "Only show entries with this outcome",
+				Choices: []discord.ApplicationCommandOptionChoiceString{
+					{Name: "success", Value: "success"},
+					{Name: "info", Value: "info"},
+					{Name: "decline", Value: "decline"},
+					{Name: "warn", Value: "warn"},
+					{Name: "error", Value: "error"},
+					{Name: "unknown", Value: "unknown"},
+				},
+			},
+			discord.ApplicationCommandOptionInt{
+				Name:        "limit",
+				Description: "Number of entries to show (default: 10)",
+				MinValue:    &auditMinLimit,
+				MaxValue:    &auditMaxLimit,
+			},
+		},
+	}
+}
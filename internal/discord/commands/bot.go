@@ -7,8 +7,32 @@ import (
 
 const BotCommandName = "bot"
 
+// BotHandlers implements /bot.
+type BotHandlers interface {
+	BotName(*Context) error
+	BotAvatar(*Context) error
+	BotBanner(*Context) error
+	BotAbout(*Context) error
+	BotStatus(*Context) error
+	BotActivity(*Context) error
+}
+
 func init() {
-	Register(BotCommand)
+	Register(Command{
+		Create:    BotCommand,
+		GuildOnly: true,
+		Bind: func(target any) Routes {
+			h := target.(BotHandlers)
+			return Routes{
+				"/bot/name":     h.BotName,
+				"/bot/avatar":   h.BotAvatar,
+				"/bot/banner":   h.BotBanner,
+				"/bot/about":    h.BotAbout,
+				"/bot/status":   h.BotStatus,
+				"/bot/activity": h.BotActivity,
+			}
+		},
+	})
 }
 
 func BotCommand() discord.ApplicationCommandCreate {
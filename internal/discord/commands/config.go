@@ -0,0 +1,82 @@
+package commands
+
+import (
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/json"
+)
+
+const ConfigCommandName = "config"
+
+// ConfigHandlers implements /config.
+type ConfigHandlers interface {
+	ConfigGet(*Context) error
+	ConfigSet(*Context) error
+	ConfigReset(*Context) error
+}
+
+func init() {
+	Register(Command{
+		Create:    ConfigCommand,
+		GuildOnly: true,
+		Bind: func(target any) Routes {
+			h := target.(ConfigHandlers)
+			return Routes{
+				"/config/get":   Require(NeedsGuildSettings, h.ConfigGet),
+				"/config/set":   Require(NeedsGuildSettings, h.ConfigSet),
+				"/config/reset": Require(NeedsGuildSettings, h.ConfigReset),
+			}
+		},
+	})
+}
+
+func ConfigCommand() discord.ApplicationCommandCreate {
+	manageGuild := json.NewNullable(discord.PermissionManageGuild)
+	return discord.SlashCommandCreate{
+		Name:                     ConfigCommandName,
+		Description:              "View and change this server's bot settings",
+		Contexts:                 []discord.InteractionContextType{discord.InteractionContextTypeGuild},
+		DefaultMemberPermissions: &manageGuild,
+		Options: []discord.ApplicationCommandOption{
+			discord.ApplicationCommandOptionSubCommand{
+				Name:        "get",
+				Description: "Show settings",
+				Options: []discord.ApplicationCommandOption{
+					discord.ApplicationCommandOptionString{
+						Name:         "key",
+						Description:  "Setting to show (default: all)",
+						Autocomplete: true,
+					},
+				},
+			},
+			discord.ApplicationCommandOptionSubCommand{
+				Name:        "set",
+				Description: "Change a setting",
+				Options: []discord.ApplicationCommandOption{
+					discord.ApplicationCommandOptionString{
+						Name:         "key",
+						Description:  "Setting to change",
+						Required:     true,
+						Autocomplete: true,
+					},
+					discord.ApplicationCommandOptionString{
+						Name:        "value",
+						Description: "New value",
+						Required:    true,
+					},
+				},
+			},
+			discord.ApplicationCommandOptionSubCommand{
+				Name:        "reset",
+				Description: "Restore a setting's default",
+				Options: []discord.ApplicationCommandOption{
+					discord.ApplicationCommandOptionString{
+						Name:         "key",
+						Description:  "Setting to reset",
+						Required:     true,
+						Autocomplete: true,
+					},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,105 @@
+package commands
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/rest"
+)
+
+// DefaultDeferAfter leaves about a second of Discord's three second deadline for the defer itself.
+const DefaultDeferAfter = 2 * time.Second
+
+// Defer answers with an ephemeral "thinking" state so the handler can take longer than Discord's
+// deadline. Later replies through the event edit that response, so handlers answer the same way either
+// way; a reply without the ephemeral flag is posted as a public follow-up instead. The router defers slow
+// handlers on its own; call Defer first when a handler is known to be slow. It does nothing once the
+// interaction has been answered or deferred, or the handler has returned.
+func (c *Context) Defer() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.responded || c.deferred || c.finished {
+		return nil
+	}
+	if err := c.respond(discord.InteractionResponseTypeDeferredCreateMessage, discord.MessageCreate{Flags: discord.MessageFlagEphemeral}); err != nil {
+		return err
+	}
+	c.deferred = true
+	return nil
+}
+
+// intercept replaces the event's responder. Once the response is deferred, a message reply edits it
+// instead of failing because the interaction was already answered.
+func (c *Context) intercept(responseType discord.InteractionResponseType, response discord.InteractionResponseData, opts ...rest.RequestOpt) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.deferred && !c.responded {
+		switch responseType {
+		case discord.InteractionResponseTypeDeferredCreateMessage:
+			return nil
+		case discord.InteractionResponseTypeCreateMessage:
+			message, _ := response.(discord.MessageCreate)
+			if err := c.replyDeferred(message, opts...); err != nil {
+				return err
+			}
+			c.responded = true
+			return nil
+		}
+	}
+
+	if err := c.respond(responseType, response, opts...); err != nil {
+		return err
+	}
+	if responseType == discord.InteractionResponseTypeDeferredCreateMessage {
+		c.deferred = true
+	} else {
+		c.responded = true
+	}
+	return nil
+}
+
+// replyDeferred answers a deferred interaction. The deferred response is ephemeral and can't be made
+// public, so an ephemeral reply edits it and any other reply is sent as a follow-up that replaces it.
+func (c *Context) replyDeferred(message discord.MessageCreate, opts ...rest.RequestOpt) error {
+	client := c.Event.Client().Rest()
+	if message.Flags.Has(discord.MessageFlagEphemeral) {
+		_, err := client.UpdateInteractionResponse(c.Event.ApplicationID(), c.Event.Token(), followUp(message), opts...)
+		return err
+	}
+
+	if _, err := client.CreateFollowupMessage(c.Event.ApplicationID(), c.Event.Token(), message, opts...); err != nil {
+		return err
+	}
+	if err := client.DeleteInteractionResponse(c.Event.ApplicationID(), c.Event.Token()); err != nil {
+		c.router.logger.Warn("deferred response cleanup failed", slog.String("command", c.Path()), slog.Any("err", err))
+	}
+	return nil
+}
+
+// abandonDeferred marks the handler finished and removes a "thinking" response it never followed up,
+// so it doesn't linger.
+func (c *Context) abandonDeferred() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.finished = true
+	if !c.deferred || c.responded {
+		return
+	}
+	c.router.logger.Warn("command deferred without a reply", slog.String("command", c.Path()))
+	if err := c.Event.Client().Rest().DeleteInteractionResponse(c.Event.ApplicationID(), c.Event.Token()); err != nil {
+		c.router.logger.Warn("deferred response cleanup failed", slog.String("command", c.Path()), slog.Any("err", err))
+	}
+}
+
+// followUp turns an ephemeral reply into an edit of the deferred response, which is already ephemeral.
+func followUp(message discord.MessageCreate) discord.MessageUpdate {
+	return discord.MessageUpdate{
+		Content:         &message.Content,
+		Embeds:          &message.Embeds,
+		Components:      &message.Components,
+		Files:           message.Files,
+		AllowedMentions: message.AllowedMentions,
+	}
+}
@@ -0,0 +1,89 @@
+package commands
+
+import (
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/json"
+)
+
+const LogCommandName = "log"
+
+// LogHandlers implements /log.
+type LogHandlers interface {
+	LogRouteAdd(*Context) error
+	LogRouteRemove(*Context) error
+	LogRouteList(*Context) error
+}
+
+func init() {
+	Register(Command{
+		Create:    LogCommand,
+		GuildOnly: true,
+		Bind: func(target any) Routes {
+			h := target.(LogHandlers)
+			return Routes{
+				"/log/add":    Require(NeedsLogRoutes, h.LogRouteAdd),
+				"/log/remove": Require(NeedsLogRoutes, h.LogRouteRemove),
+				"/log/list":   Require(NeedsLogRoutes, h.LogRouteList),
+			}
+		},
+	})
+}
+
+func LogCommand() discord.ApplicationCommandCreate {
+	manageGuild := json.NewNullable(discord.PermissionManageGuild)
+	levelChoices := []discord.ApplicationCommandOptionChoiceString{
+		{Name: "debug", Value: "debug"},
+		{Name: "info", Value: "info"},
+		{Name: "warn", Value: "warn"},
+		{Name: "error", Value: "error"},
+	}
+	return discord.SlashCommandCreate{
+		Name:                     LogCommandName,
+		Description:              "Route bot log events to channels",
+		Contexts:                 []discord.InteractionContextType{discord.InteractionContextTypeGuild},
+		DefaultMemberPermissions: &manageGuild,
+		Options: []discord.ApplicationCommandOption{
+			discord.ApplicationCommandOptionSubCommand{
+				Name:        "add",
+				Description: "Send log events to a channel",
+				Options: []discord.ApplicationCommandOption{
+					discord.ApplicationCommandOptionChannel{
+						Name:         "channel",
+						Description:  "Channel to post log events in",
+						Required:     true,
+						ChannelTypes: []discord.ChannelType{discord.ChannelTypeGuildText, discord.ChannelTypeGuildNews},
+					},
+					discord.ApplicationCommandOptionString{
+						Name:        "category",
+						Description: "Only route this category, e.g. roles (default: all)",
+					},
+					discord.ApplicationCommandOptionString{
+						Name:        "level",
+						Description: "Minimum level to route (default: info)",
+						Choices:     levelChoices,
+					},
+				},
+			},
+			discord.ApplicationCommandOptionSubCommand{
+				Name:        "remove",
+				Description: "Stop sending log events to a channel",
+				Options: []discord.ApplicationCommandOption{
+					discord.ApplicationCommandOptionChannel{
+						Name:         "channel",
+						Description:  "Channel to stop posting in",
+						Required:     true,
+						ChannelTypes: []discord.ChannelType{discord.ChannelTypeGuildText, discord.ChannelTypeGuildNews},
+					},
+					discord.ApplicationCommandOptionString{
+						Name:        "category",
+						Description: "Category of the route to remove (default: every route for the channel)",
+					},
+				},
+			},
+			discord.ApplicationCommandOptionSubCommand{
+				Name:        "list",
+				Description: "List log routes",
+			},
+		},
+	}
+}
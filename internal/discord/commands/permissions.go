@@ -0,0 +1,97 @@
+package commands
+
+import (
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/json"
+)
+
+const PermissionsCommandName = "permissions"
+
+// PermissionsHandlers implements /permissions.
+type PermissionsHandlers interface {
+	CommandEnable(*Context) error
+	CommandDisable(*Context) error
+	CommandAllow(*Context) error
+	CommandRevoke(*Context) error
+	CommandRuleList(*Context) error
+}
+
+func init() {
+	Register(Command{
+		Create:    PermissionsCommand,
+		GuildOnly: true,
+		Bind: func(target any) Routes {
+			h := target.(PermissionsHandlers)
+			return Routes{
+				"/permissions/enable":  Require(NeedsCommandPermissions, h.CommandEnable),
+				"/permissions/disable": Require(NeedsCommandPermissions, h.CommandDisable),
+				"/permissions/allow":   Require(NeedsCommandPermissions, h.CommandAllow),
+				"/permissions/revoke":  Require(NeedsCommandPermissions, h.CommandRevoke),
+				"/permissions/list":    Require(NeedsCommandPermissions, h.CommandRuleList),
+			}
+		},
+	})
+}
+
+func PermissionsCommand() discord.ApplicationCommandCreate {
+	manageGuild := json.NewNullable(discord.PermissionManageGuild)
+	return discord.SlashCommandCreate{
+		Name:                     PermissionsCommandName,
+		Description:              "Turn bot commands on or off and choose which roles can use them",
+		Contexts:                 []discord.InteractionContextType{discord.InteractionContextTypeGuild},
+		DefaultMemberPermissions: &manageGuild,
+		Options: []discord.ApplicationCommandOption{
+			discord.ApplicationCommandOptionSubCommand{
+				Name:        "enable",
+				Description: "Turn a disabled command back on",
+				Options: []discord.ApplicationCommandOption{
+					commandPathOption("Command or subcommand, e.g. /reaction or /role auto add"),
+				},
+			},
+			discord.ApplicationCommandOptionSubCommand{
+				Name:        "disable",
+				Description: "Turn a command and its subcommands off in this server",
+				Options: []discord.ApplicationCommandOption{
+					commandPathOption("Command or subcommand, e.g. /reaction or /role auto add"),
+				},
+			},
+			discord.ApplicationCommandOptionSubCommand{
+				Name:        "allow",
+				Description: "Let a role use a command without its default permissions",
+				Options: []discord.ApplicationCommandOption{
+					commandPathOption("Command or subcommand the role may use"),
+					discord.ApplicationCommandOptionRole{
+						Name:        "role",
+						Description: "Role to grant",
+						Required:    true,
+					},
+				},
+			},
+			discord.ApplicationCommandOptionSubCommand{
+				Name:        "revoke",
+				Description: "Take back a role's access to a command",
+				Options: []discord.ApplicationCommandOption{
+					commandPathOption("Command or subcommand the role was granted"),
+					discord.ApplicationCommandOptionRole{
+						Name:        "role",
+						Description: "Role to revoke",
+						Required:    true,
+					},
+				},
+			},
+			discord.ApplicationCommandOptionSubCommand{
+				Name:        "list",
+				Description: "Show disabled commands and role grants",
+			},
+		},
+	}
+}
+
+func commandPathOption(description string) discord.ApplicationCommandOption {
+	return discord.ApplicationCommandOptionString{
+		Name:         "command",
+		Description:  description,
+		Required:     true,
+		Autocomplete: true,
+	}
+}
@@ -1,38 +1,283 @@
 package commands
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"log/slog"
+	"sort"
 
 	"github.com/disgoorg/disgo/bot"
+	"github.com/disgoorg/disgo/discord"
 	"github.com/disgoorg/snowflake/v2"
 )
 
+// Status is how a command compares between the code and Discord.
+type Status string
+
+const (
+	StatusInSync  Status = "in sync"
+	StatusNew     Status = "new"
+	StatusChanged Status = "changed"
+	StatusStale   Status = "stale"
+)
+
+// Change is one command in a sync plan. ID is set for commands Discord already has.
+type Change struct {
+	Name   string
+	ID     snowflake.ID
+	Status Status
+	create discord.ApplicationCommandCreate
+}
+
+// Plan compares the registered commands with what Discord has, sorted by name.
+type Plan []Change
+
+// Pending reports whether applying the plan would change anything.
+func (p Plan) Pending() bool {
+	for _, change := range p {
+		if change.Status != StatusInSync {
+			return true
+		}
+	}
+	return false
+}
+
+// Count returns how many commands have status.
+func (p Plan) Count(status Status) int {
+	count := 0
+	for _, change := range p {
+		if change.Status == status {
+			count++
+		}
+	}
+	return count
+}
+
+// Diff compares wanted commands with existing ones by name and type.
+func Diff(existing []discord.ApplicationCommand, wanted []discord.ApplicationCommandCreate) Plan {
+	type commandKey struct {
+		name        string
+		commandType discord.ApplicationCommandType
+	}
+
+	current := make(map[commandKey]discord.ApplicationCommand, len(existing))
+	for _, command := range existing {
+		current[commandKey{command.Name(), command.Type()}] = command
+	}
+
+	plan := make(Plan, 0, len(wanted)+len(existing))
+	for _, create := range wanted {
+		key := commandKey{create.CommandName(), create.Type()}
+		command, ok := current[key]
+		delete(current, key)
+
+		change := Change{Name: create.CommandName(), Status: StatusNew, create: create}
+		if ok {
+			change.ID = command.ID()
+			change.Status = StatusChanged
+			if sameCommand(command, create) {
+				change.Status = StatusInSync
+			}
+		}
+		plan = append(plan, change)
+	}
+	for _, command := range current {
+		plan = append(plan, Change{Name: command.Name(), ID: command.ID(), Status: StatusStale})
+	}
+
+	sort.Slice(plan, func(i, j int) bool {
+		return plan[i].Name < plan[j].Name
+	})
+	return plan
+}
+
+// commandShape is the part of a command we define. Discord fills in IDs, versions and defaults on its side,
+// so commands are compared on this rather than on their raw payloads.
+type commandShape struct {
+	Type                     discord.ApplicationCommandType       `json:"type"`
+	Name                     string                               `json:"name"`
+	NameLocalizations        map[discord.Locale]string            `json:"name_localizations,omitempty"`
+	Description              string                               `json:"description,omitempty"`
+	DescriptionLocalizations map[discord.Locale]string            `json:"description_localizations,omitempty"`
+	Options                  []discord.ApplicationCommandOption   `json:"options,omitempty"`
+	DefaultMemberPermissions discord.Permissions                  `json:"default_member_permissions"`
+	NSFW                     bool                                 `json:"nsfw"`
+	IntegrationTypes         []discord.ApplicationIntegrationType `json:"integration_types,omitempty"`
+	Contexts                 []discord.InteractionContextType     `json:"contexts,omitempty"`
+}
+
+// sameCommand reports whether Discord's copy matches the definition. Only slash commands are compared;
+// other types always count as changed.
+func sameCommand(command discord.ApplicationCommand, create discord.ApplicationCommandCreate) bool {
+	slash, ok := command.(discord.SlashCommand)
+	if !ok {
+		return false
+	}
+	slashCreate, ok := create.(discord.SlashCommandCreate)
+	if !ok {
+		return false
+	}
+
+	have := commandShape{
+		Type:                     slash.Type(),
+		Name:                     slash.Name(),
+		NameLocalizations:        slash.NameLocalizations(),
+		Description:              slash.Description,
+		DescriptionLocalizations: slash.DescriptionLocalizations,
+		Options:                  slash.Options,
+		DefaultMemberPermissions: slash.DefaultMemberPermissions(),
+		NSFW:                     slash.NSFW(),
+		IntegrationTypes:         slash.IntegrationTypes(),
+		Contexts:                 slash.Contexts(),
+	}
+	want := commandShape{
+		Type:                     slashCreate.Type(),
+		Name:                     slashCreate.Name,
+		NameLocalizations:        slashCreate.NameLocalizations,
+		Description:              slashCreate.Description,
+		DescriptionLocalizations: slashCreate.DescriptionLocalizations,
+		Options:                  slashCreate.Options,
+		IntegrationTypes:         slashCreate.IntegrationTypes,
+		Contexts:                 slashCreate.Contexts,
+	}
+	// Discord reports unset permissions as 0, so an explicit 0 and no default compare equal.
+	if slashCreate.DefaultMemberPermissions != nil {
+		want.DefaultMemberPermissions = slashCreate.DefaultMemberPermissions.Value()
+	}
+	if slashCreate.NSFW != nil {
+		want.NSFW = *slashCreate.NSFW
+	}
+	// Commands without integration types are installed to guilds only.
+	if len(want.IntegrationTypes) == 0 {
+		want.IntegrationTypes = []discord.ApplicationIntegrationType{discord.ApplicationIntegrationTypeGuildInstall}
+	}
+
+	haveJSON, err := json.Marshal(have)
+	if err != nil {
+		return false
+	}
+	wantJSON, err := json.Marshal(want)
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(haveJSON, wantJSON)
+}
+
+// PlanCommands fetches the commands Discord has globally, or in guildID when set, and diffs them against All.
+func PlanCommands(client bot.Client, guildID *snowflake.ID) (Plan, error) {
+	existing, err := fetchCommands(client, guildID)
+	if err != nil {
+		return nil, err
+	}
+	return Diff(existing, All()), nil
+}
+
+// SyncCommands pushes only what differs: new and changed commands are created (Discord replaces a command
+// with the same name) and stale ones deleted. It returns the plan it applied.
+func SyncCommands(client bot.Client, logger *slog.Logger, guildID *snowflake.ID) (Plan, error) {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	plan, err := PlanCommands(client, guildID)
+	if err != nil {
+		return nil, fmt.Errorf("fetch commands: %w", err)
+	}
+
+	applicationID := client.ApplicationID()
+	rest := client.Rest()
+	var errs []error
+	for _, change := range plan {
+		switch change.Status {
+		case StatusNew, StatusChanged:
+			if guildID != nil {
+				_, err = rest.CreateGuildCommand(applicationID, *guildID, change.create)
+			} else {
+				_, err = rest.CreateGlobalCommand(applicationID, change.create)
+			}
+		case StatusStale:
+			if guildID != nil {
+				err = rest.DeleteGuildCommand(applicationID, *guildID, change.ID)
+			} else {
+				err = rest.DeleteGlobalCommand(applicationID, change.ID)
+			}
+		default:
+			continue
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s /%s: %w", change.Status, change.Name, err))
+		}
+	}
+
+	return plan, errors.Join(errs...)
+}
+
+// RegisterCommands syncs the commands globally, or with the dev guild when set, and logs what changed.
 func RegisterCommands(client bot.Client, logger *slog.Logger, devGuildID *snowflake.ID) error {
 	if logger == nil {
 		logger = slog.Default()
 	}
 
-	cmds := All()
-	if len(cmds) == 0 {
+	if len(All()) == 0 {
 		logger.Warn("no commands registered")
 		return nil
 	}
 
+	scope := []any{slog.String("scope", "global")}
 	if devGuildID != nil {
-		_, err := client.Rest().SetGuildCommands(client.ApplicationID(), *devGuildID, cmds)
-		if err != nil {
-			logger.Error("failed to register guild commands", slog.Any("err", err))
-			return err
-		}
-		logger.Info("discord guild commands registered", slog.Int("count", len(cmds)), slog.String("guild_id", devGuildID.String()))
-		return nil
+		scope = []any{slog.String("scope", "guild"), slog.String("guild_id", devGuildID.String())}
 	}
 
-	_, err := client.Rest().SetGlobalCommands(client.ApplicationID(), cmds)
+	plan, err := SyncCommands(client, logger, devGuildID)
 	if err != nil {
-		logger.Error("failed to register commands", slog.Any("err", err))
+		logger.Error("failed to sync discord commands", append(scope, slog.Any("err", err))...)
 		return err
 	}
-	logger.Info("discord commands registered", slog.Int("count", len(cmds)))
+	if !plan.Pending() {
+		logger.Info("discord commands up to date", append(scope, slog.Int("count", len(plan)))...)
+		return nil
+	}
+	logger.Info(
+		"discord commands synced",
+		append(scope,
+			slog.Int("created", plan.Count(StatusNew)),
+			slog.Int("updated", plan.Count(StatusChanged)),
+			slog.Int("deleted", plan.Count(StatusStale)),
+			slog.Int("unchanged", plan.Count(StatusInSync)),
+		)...,
+	)
 	return nil
 }
+
+// ClearGuildCommands removes every command registered directly with a guild, e.g. when the dev guild changes.
+// Guilds without commands are left alone. It returns how many commands were removed.
+func ClearGuildCommands(client bot.Client, logger *slog.Logger, guildID snowflake.ID) (int, error) {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	existing, err := fetchCommands(client, &guildID)
+	if err != nil {
+		logger.Error("failed to fetch guild commands", slog.String("guild_id", guildID.String()), slog.Any("err", err))
+		return 0, err
+	}
+	if len(existing) == 0 {
+		return 0, nil
+	}
+
+	if _, err := client.Rest().SetGuildCommands(client.ApplicationID(), guildID, []discord.ApplicationCommandCreate{}); err != nil {
+		logger.Error("failed to clear guild commands", slog.String("guild_id", guildID.String()), slog.Any("err", err))
+		return 0, err
+	}
+	logger.Info("discord guild commands cleared", slog.String("guild_id", guildID.String()), slog.Int("count", len(existing)))
+	return len(existing), nil
+}
+
+func fetchCommands(client bot.Client, guildID *snowflake.ID) ([]discord.ApplicationCommand, error) {
+	if guildID != nil {
+		return client.Rest().GetGuildCommands(client.ApplicationID(), *guildID, true)
+	}
+	return client.Rest().GetGlobalCommands(client.ApplicationID(), true)
+}
@@ -5,24 +5,33 @@ import "github.com/disgoorg/disgo/discord"
 // Builder returns a command definition to register with Discord.
 type Builder func() discord.ApplicationCommandCreate
 
-var builders []Builder
+// Command is a command definition together with its handlers.
+type Command struct {
+	Create Builder
+	// GuildOnly declines the command outside servers before any handler runs.
+	GuildOnly bool
+	// Middleware wraps every route of this command, inside the router's own.
+	Middleware []Middleware
+	// Bind returns the handlers for the command's paths. target is the value passed to NewRouter;
+	// Bind asserts it to the interface the command needs.
+	Bind func(target any) Routes
+}
+
+var registry []Command
 
-// Register adds a command builder. Use one file per command and register in init.
-func Register(builder Builder) {
-	if builder == nil {
+// Register adds a command. Use one file per command and register in init.
+func Register(command Command) {
+	if command.Create == nil {
 		return
 	}
-	builders = append(builders, builder)
+	registry = append(registry, command)
 }
 
-// All returns all registered commands.
+// All returns all registered command definitions.
 func All() []discord.ApplicationCommandCreate {
-	commands := make([]discord.ApplicationCommandCreate, 0, len(builders))
-	for _, builder := range builders {
-		if builder == nil {
-			continue
-		}
-		commands = append(commands, builder())
+	commands := make([]discord.ApplicationCommandCreate, 0, len(registry))
+	for _, command := range registry {
+		commands = append(commands, command.Create())
 	}
 	return commands
 }
@@ -4,8 +4,22 @@ import "github.com/disgoorg/disgo/discord"
 
 const RoleSelfCommandName = "toggle-role"
 
+// RoleSelfHandlers implements /toggle-role.
+type RoleSelfHandlers interface {
+	RoleToggleSelf(*Context) error
+}
+
 func init() {
-	Register(RoleSelfCommand)
+	Register(Command{
+		Create:    RoleSelfCommand,
+		GuildOnly: true,
+		Bind: func(target any) Routes {
+			h := target.(RoleSelfHandlers)
+			return Routes{
+				"/" + RoleSelfCommandName: Require(NeedsRoleToggles, h.RoleToggleSelf),
+			}
+		},
+	})
 }
 
 func RoleSelfCommand() discord.ApplicationCommandCreate {
@@ -14,9 +28,10 @@ func RoleSelfCommand() discord.ApplicationCommandCreate {
 		Description: "Toggle self-assignable roles",
 		Contexts:    []discord.InteractionContextType{discord.InteractionContextTypeGuild},
 		Options: []discord.ApplicationCommandOption{
-			discord.ApplicationCommandOptionRole{
-				Name:        "role",
-				Description: "Role to toggle (leave empty to list)",
+			discord.ApplicationCommandOptionString{
+				Name:         "role",
+				Description:  "Role to toggle (leave empty to list)",
+				Autocomplete: true,
 			},
 		},
 	}
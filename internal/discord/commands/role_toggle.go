@@ -7,8 +7,41 @@ import (
 
 const RoleToggleCommandName = "role"
 
+var (
+	autoRoleMinDelay = 0
+	autoRoleMaxDelay = 86400
+)
+
+// RoleHandlers implements /role.
+type RoleHandlers interface {
+	RoleToggleAdd(*Context) error
+	RoleToggleRemove(*Context) error
+	RoleMessageCreate(*Context) error
+	RoleMessageRemove(*Context) error
+	RoleMessageList(*Context) error
+	AutoRoleAdd(*Context) error
+	AutoRoleRemove(*Context) error
+	AutoRoleList(*Context) error
+}
+
 func init() {
-	Register(RoleToggleCommand)
+	Register(Command{
+		Create:    RoleToggleCommand,
+		GuildOnly: true,
+		Bind: func(target any) Routes {
+			h := target.(RoleHandlers)
+			return Routes{
+				"/role/add":            Require(NeedsRoleToggles, h.RoleToggleAdd),
+				"/role/remove":         Require(NeedsRoleToggles, h.RoleToggleRemove),
+				"/role/message-create": Require(NeedsStaticMessages, h.RoleMessageCreate),
+				"/role/message-remove": Require(NeedsStaticMessages, h.RoleMessageRemove),
+				"/role/message-list":   Require(NeedsStaticMessages, h.RoleMessageList),
+				"/role/auto/add":       Require(NeedsAutoRoles, h.AutoRoleAdd),
+				"/role/auto/remove":    Require(NeedsAutoRoles, h.AutoRoleRemove),
+				"/role/auto/list":      Require(NeedsAutoRoles, h.AutoRoleList),
+			}
+		},
+	})
 }
 
 func RoleToggleCommand() discord.ApplicationCommandCreate {
@@ -36,6 +69,18 @@ func RoleToggleCommand() discord.ApplicationCommandCreate {
 						Name:        "permissions",
 						Description: "Permission integer required to toggle (default: 0)",
 					},
+					discord.ApplicationCommandOptionString{
+						Name:        "category",
+						Description: "Optional category to group the role under in role lists",
+					},
+					discord.ApplicationCommandOptionString{
+						Name:        "emoji",
+						Description: "Optional emoji shown next to the role (unicode or <:name:id>)",
+					},
+					discord.ApplicationCommandOptionInt{
+						Name:        "order",
+						Description: "Position in role lists using custom order (lower comes first)",
+					},
 				},
 			},
 			discord.ApplicationCommandOptionSubCommand{
@@ -67,6 +112,23 @@ func RoleToggleCommand() discord.ApplicationCommandCreate {
 						Name:        "description",
 						Description: "Optional description override",
 					},
+					discord.ApplicationCommandOptionString{
+						Name:        "order",
+						Description: "How to order roles (default: position)",
+						Choices: []discord.ApplicationCommandOptionChoiceString{
+							{Name: "Discord role position", Value: "position"},
+							{Name: "Custom order", Value: "custom"},
+							{Name: "Name", Value: "name"},
+						},
+					},
+					discord.ApplicationCommandOptionBool{
+						Name:        "show_emoji",
+						Description: "Show each role's emoji (default: true)",
+					},
+					discord.ApplicationCommandOptionBool{
+						Name:        "show_counts",
+						Description: "Show member counts per role (requires the members intent)",
+					},
 				},
 			},
 			discord.ApplicationCommandOptionSubCommand{
@@ -84,6 +146,65 @@ func RoleToggleCommand() discord.ApplicationCommandCreate {
 				Name:        "message-list",
 				Description: "List static role list messages",
 			},
+			discord.ApplicationCommandOptionSubCommandGroup{
+				Name:        "auto",
+				Description: "Manage roles assigned on join or restored on rejoin",
+				Options: []discord.ApplicationCommandOptionSubCommand{
+					{
+						Name:        "add",
+						Description: "Assign a role on join or restore it on rejoin",
+						Options: []discord.ApplicationCommandOption{
+							discord.ApplicationCommandOptionRole{
+								Name:        "role",
+								Description: "Role to assign",
+								Required:    true,
+							},
+							discord.ApplicationCommandOptionString{
+								Name:        "mode",
+								Description: "Assign on every join, or restore only if the member had it when leaving",
+								Required:    true,
+								Choices: []discord.ApplicationCommandOptionChoiceString{
+									{Name: "join", Value: "join"},
+									{Name: "sticky", Value: "sticky"},
+								},
+							},
+							discord.ApplicationCommandOptionInt{
+								Name:        "delay",
+								Description: "Seconds to wait before assigning (default: 0)",
+								MinValue:    &autoRoleMinDelay,
+								MaxValue:    &autoRoleMaxDelay,
+							},
+							discord.ApplicationCommandOptionBool{
+								Name:        "after_screening",
+								Description: "Wait until the member passes membership screening (join mode only)",
+							},
+						},
+					},
+					{
+						Name:        "remove",
+						Description: "Stop assigning a role automatically",
+						Options: []discord.ApplicationCommandOption{
+							discord.ApplicationCommandOptionRole{
+								Name:        "role",
+								Description: "Role to remove",
+								Required:    true,
+							},
+							discord.ApplicationCommandOptionString{
+								Name:        "mode",
+								Description: "Only remove this mode (default: both)",
+								Choices: []discord.ApplicationCommandOptionChoiceString{
+									{Name: "join", Value: "join"},
+									{Name: "sticky", Value: "sticky"},
+								},
+							},
+						},
+					},
+					{
+						Name:        "list",
+						Description: "List automatic roles",
+					},
+				},
+			},
 		},
 	}
 }
@@ -0,0 +1,382 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"antartica-bot/internal/discord/embeds"
+	"antartica-bot/internal/metrics"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/events"
+	"github.com/disgoorg/disgo/rest"
+	"github.com/disgoorg/snowflake/v2"
+)
+
+// Dependencies a route can require with Require. Routes whose dependency is missing answer
+// "<name> is not configured." instead of running.
+const (
+	NeedsRoleToggles        = "Role toggle store"
+	NeedsReactionTracks     = "Reaction track store"
+	NeedsStaticMessages     = "Static message store"
+	NeedsAutoRoles          = "Auto role store"
+	NeedsLogRoutes          = "Log route store"
+	NeedsAuditLog           = "Audit log store"
+	NeedsGuildSettings      = "Guild settings store"
+	NeedsCommandPermissions = "Command permission store"
+)
+
+// Context carries one slash command invocation through middleware and handlers.
+type Context struct {
+	Event *events.ApplicationCommandInteractionCreate
+	Data  discord.SlashCommandInteractionData
+
+	router *Router
+
+	mu        sync.Mutex
+	respond   func(discord.InteractionResponseType, discord.InteractionResponseData, ...rest.RequestOpt) error
+	deferred  bool
+	responded bool
+	// finished is set once the handler has returned, so a late auto-defer doesn't leave "thinking" behind.
+	finished bool
+}
+
+// Path is the full command path, e.g. /role/auto/add.
+func (c *Context) Path() string {
+	return c.Data.CommandPath()
+}
+
+// GuildID is the guild the command ran in, or 0 in DMs. Guild-only commands can rely on it being set.
+func (c *Context) GuildID() snowflake.ID {
+	if guildID := c.Event.GuildID(); guildID != nil {
+		return *guildID
+	}
+	return 0
+}
+
+// CommandName is the top-level command, e.g. role for /role/auto/add.
+func (c *Context) CommandName() string {
+	return c.Data.CommandName()
+}
+
+// DefaultPermissions returns the permissions the command's definition asks Discord to require, and false
+// when it leaves the command open to everyone. Discord only applies them in its client.
+func (c *Context) DefaultPermissions() (discord.Permissions, bool) {
+	return c.router.DefaultPermissions(c.Path())
+}
+
+// MemberPermissions returns the invoking member's permissions in the channel, or 0 outside a server.
+// Administrators have every permission.
+func (c *Context) MemberPermissions() discord.Permissions {
+	member := c.Event.Member()
+	if member == nil {
+		return 0
+	}
+	permissions := member.Permissions
+	if permissions == 0 {
+		permissions = c.Event.Client().Caches().MemberPermissions(member.Member)
+	}
+	if permissions.Has(discord.PermissionAdministrator) {
+		return discord.PermissionsAll
+	}
+	return permissions
+}
+
+// Responded reports whether the interaction has been answered. A deferred response that hasn't been
+// followed up doesn't count.
+func (c *Context) Responded() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.responded
+}
+
+type HandlerFunc func(*Context) error
+
+// Middleware wraps a handler. Middleware runs in the order given, outermost first.
+type Middleware func(next HandlerFunc) HandlerFunc
+
+// Routes maps command paths to their handlers.
+type Routes map[string]HandlerFunc
+
+// ReplyError is an error whose message is shown to the user as is.
+// Handlers return it for expected failures; any other error gets a generic reply and is logged.
+type ReplyError struct {
+	Tone    embeds.EmbedTone
+	Message string
+}
+
+func (e *ReplyError) Error() string {
+	return e.Message
+}
+
+// Reply returns a ReplyError with a formatted message.
+func Reply(tone embeds.EmbedTone, format string, args ...any) error {
+	return &ReplyError{Tone: tone, Message: fmt.Sprintf(format, args...)}
+}
+
+// Require runs next only when dependency is configured.
+func Require(dependency string, next HandlerFunc) HandlerFunc {
+	return func(c *Context) error {
+		if c.router.configured != nil && !c.router.configured(dependency) {
+			return Reply(embeds.EmbedError, "%s is not configured.", dependency)
+		}
+		return next(c)
+	}
+}
+
+// RequirePermissions runs next only when the invoking member has every permission in required.
+func RequirePermissions(required discord.Permissions, next HandlerFunc) HandlerFunc {
+	return func(c *Context) error {
+		if c.Event.Member() == nil {
+			return Reply(embeds.EmbedDecline, "Missing member data.")
+		}
+		permissions := c.MemberPermissions()
+		if !permissions.Has(required) {
+			return Reply(embeds.EmbedDecline, "You need the %s permission.", permissionNames(required.Remove(permissions)))
+		}
+		return next(c)
+	}
+}
+
+func permissionNames(permissions discord.Permissions) string {
+	names := strings.Split(permissions.String(), " | ")
+	for i, name := range names {
+		names[i] = splitWords(name)
+	}
+	return strings.Join(names, ", ")
+}
+
+// splitWords turns ManageRoles into Manage Roles.
+func splitWords(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		if i > 0 && r >= 'A' && r <= 'Z' {
+			b.WriteByte(' ')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// guildOnly declines commands used outside a server.
+func guildOnly(next HandlerFunc) HandlerFunc {
+	return func(c *Context) error {
+		if c.Event.GuildID() == nil {
+			return Reply(embeds.EmbedDecline, "This command can only be used in a server.")
+		}
+		return next(c)
+	}
+}
+
+// recoverPanics turns a panicking handler into an error so the user still gets a reply.
+func recoverPanics(next HandlerFunc) HandlerFunc {
+	return func(c *Context) (err error) {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				c.router.logger.Error(
+					"command handler panicked",
+					slog.String("command", c.Path()),
+					slog.Any("panic", recovered),
+					slog.String("stack", string(debug.Stack())),
+				)
+				err = fmt.Errorf("panic: %v", recovered)
+			}
+		}()
+		return next(c)
+	}
+}
+
+// respondErrors answers handler errors the same way everywhere: ReplyErrors as written, anything else
+// with a generic message. Nothing is sent if the handler already answered; a deferred response is edited.
+func respondErrors(next HandlerFunc) HandlerFunc {
+	return func(c *Context) error {
+		err := next(c)
+		if err == nil {
+			return nil
+		}
+
+		var reply *ReplyError
+		if !errors.As(err, &reply) {
+			c.router.logger.Error("command failed", slog.String("command", c.Path()), slog.Any("err", err))
+			reply = &ReplyError{Tone: embeds.EmbedError, Message: "Something went wrong while running this command."}
+		}
+		if c.Responded() {
+			return err
+		}
+
+		embed := embeds.BuildEmbed(embeds.EmbedTemplate{Tone: reply.Tone, Description: reply.Message})
+		if respondErr := c.Event.CreateMessage(discord.MessageCreate{
+			Embeds: []discord.Embed{embed},
+			Flags:  discord.MessageFlagEphemeral,
+		}); respondErr != nil {
+			c.router.logger.Warn("command error response failed", slog.String("command", c.Path()), slog.Any("err", respondErr))
+		}
+		return err
+	}
+}
+
+// observe records timing metrics and logs every invocation at debug level.
+func observe(next HandlerFunc) HandlerFunc {
+	return func(c *Context) error {
+		started := time.Now()
+		err := next(c)
+		duration := time.Since(started)
+
+		metrics.CommandHandled(c.Path(), duration)
+		c.router.logger.Debug(
+			"command handled",
+			slog.String("command", c.Path()),
+			slog.String("user_id", c.Event.User().ID.String()),
+			slog.String("guild_id", c.GuildID().String()),
+			slog.Duration("duration", duration),
+			slog.Bool("failed", err != nil),
+		)
+		return err
+	}
+}
+
+type RouterOptions struct {
+	Logger *slog.Logger
+	// DeferAfter is how long a handler may run before the router defers its response. Zero uses
+	// DefaultDeferAfter; a negative value turns automatic deferral off.
+	DeferAfter time.Duration
+	// Configured reports whether a dependency named in Require is available. Nil treats all as available.
+	Configured func(dependency string) bool
+	// Middleware runs around every command, inside the router's timing, logging, error responses and
+	// panic recovery, outermost first. A ReplyError it returns is sent like a handler's.
+	Middleware []Middleware
+}
+
+// Router dispatches slash commands to the handlers registered with their definitions.
+type Router struct {
+	logger     *slog.Logger
+	configured func(string) bool
+	deferAfter time.Duration
+	routes     map[string]HandlerFunc
+	defaults   map[string]discord.Permissions
+	unknown    HandlerFunc
+}
+
+// NewRouter binds every registered command to target, which must implement the handler interfaces
+// the commands declare. A missing implementation panics, so it shows up at startup.
+func NewRouter(target any, opts RouterOptions) *Router {
+	logger := opts.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	deferAfter := opts.DeferAfter
+	if deferAfter == 0 {
+		deferAfter = DefaultDeferAfter
+	}
+
+	router := &Router{
+		logger:     logger,
+		configured: opts.Configured,
+		deferAfter: deferAfter,
+		routes:     make(map[string]HandlerFunc),
+		defaults:   make(map[string]discord.Permissions),
+	}
+
+	outer := append([]Middleware{observe, respondErrors, recoverPanics}, opts.Middleware...)
+
+	for _, command := range registry {
+		if command.Bind == nil {
+			continue
+		}
+		create := command.Create()
+		name := create.CommandName()
+		if slash, ok := create.(discord.SlashCommandCreate); ok && slash.DefaultMemberPermissions != nil && !slash.DefaultMemberPermissions.IsNull() {
+			router.defaults[name] = slash.DefaultMemberPermissions.Value()
+		}
+
+		inner := append([]Middleware(nil), outer...)
+		if command.GuildOnly {
+			inner = append(inner, guildOnly)
+		}
+		inner = append(inner, command.Middleware...)
+
+		for path, handler := range command.Bind(target) {
+			if path != "/"+name && !strings.HasPrefix(path, "/"+name+"/") {
+				panic(fmt.Sprintf("commands: route %s does not belong to /%s", path, name))
+			}
+			router.routes[path] = chain(handler, inner)
+		}
+	}
+
+	router.unknown = chain(func(*Context) error {
+		return Reply(embeds.EmbedWarn, "Unknown subcommand.")
+	}, outer)
+
+	return router
+}
+
+// Handle runs the handler for a slash command. Other command types are ignored.
+func (r *Router) Handle(event *events.ApplicationCommandInteractionCreate) {
+	if event.ApplicationCommandInteraction.Data.Type() != discord.ApplicationCommandTypeSlash {
+		return
+	}
+
+	c := &Context{
+		Event:   event,
+		Data:    event.SlashCommandInteractionData(),
+		router:  r,
+		respond: event.Respond,
+	}
+	event.Respond = c.intercept
+
+	handler, ok := r.routes[c.Path()]
+	if !ok {
+		handler = r.unknown
+	}
+
+	if r.deferAfter > 0 {
+		timer := time.AfterFunc(r.deferAfter, func() {
+			if err := c.Defer(); err != nil {
+				r.logger.Warn("command defer failed", slog.String("command", c.Path()), slog.Any("err", err))
+			}
+		})
+		_ = handler(c)
+		timer.Stop()
+	} else {
+		_ = handler(c)
+	}
+	c.abandonDeferred()
+}
+
+// DefaultPermissions returns the default member permissions of the command path belongs to, and false when
+// the command is open to everyone.
+func (r *Router) DefaultPermissions(path string) (discord.Permissions, bool) {
+	name, _, _ := strings.Cut(strings.TrimPrefix(path, "/"), "/")
+	permissions, ok := r.defaults[name]
+	return permissions, ok
+}
+
+// Paths returns every routed command path along with the command and group paths above it, sorted.
+func (r *Router) Paths() []string {
+	seen := make(map[string]bool)
+	for path := range r.routes {
+		for prefix := path; prefix != ""; prefix = prefix[:strings.LastIndex(prefix, "/")] {
+			seen[prefix] = true
+		}
+	}
+	paths := make([]string, 0, len(seen))
+	for path := range seen {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+func chain(handler HandlerFunc, middleware []Middleware) HandlerFunc {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		handler = middleware[i](handler)
+	}
+	return handler
+}
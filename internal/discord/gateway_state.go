@@ -0,0 +1,52 @@
+package discord
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/disgoorg/disgo/bot"
+	"github.com/disgoorg/disgo/events"
+	"github.com/disgoorg/disgo/gateway"
+)
+
+// gatewayState tracks gateway activity for the health endpoints. Heartbeat acks count as events,
+// so a connected bot in a quiet guild still reports recent activity.
+type gatewayState struct {
+	lastEvent atomic.Int64
+	resumes   atomic.Uint64
+}
+
+func (s *gatewayState) onEvent(event bot.Event) {
+	s.lastEvent.Store(time.Now().UnixNano())
+	if _, ok := event.(*events.Resumed); ok {
+		s.resumes.Add(1)
+	}
+}
+
+func (b *Bot) GatewayStatus() gateway.Status {
+	if gw := b.client.Gateway(); gw != nil {
+		return gw.Status()
+	}
+	return gateway.StatusUnconnected
+}
+
+func (b *Bot) GatewayLatency() time.Duration {
+	if gw := b.client.Gateway(); gw != nil {
+		return gw.Latency()
+	}
+	return 0
+}
+
+// LastGatewayEvent returns when the gateway last delivered anything. Zero until the first event.
+func (b *Bot) LastGatewayEvent() time.Time {
+	nanos := b.gateway.lastEvent.Load()
+	if nanos == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, nanos)
+}
+
+// GatewayResumes counts sessions resumed after a dropped connection.
+func (b *Bot) GatewayResumes() uint64 {
+	return b.gateway.resumes.Load()
+}
@@ -7,6 +7,8 @@ import (
 	"regexp"
 	"strings"
 
+	"antartica-bot/internal/discord/commands"
+
 	"github.com/disgoorg/disgo/discord"
 	"github.com/disgoorg/disgo/events"
 	"github.com/disgoorg/snowflake/v2"
@@ -23,53 +25,25 @@ type leaderboardMessageConfig struct {
 
 const staticMessageTypeLeaderboard = "reaction_leaderboard"
 
-func (h *Handler) handleReactionCommand(event *events.ApplicationCommandInteractionCreate, data discord.SlashCommandInteractionData) {
-	if event.GuildID() == nil {
-		_ = respondEphemeralTone(event, EmbedDecline, "This command can only be used in a server.")
-		return
-	}
-
-	switch data.CommandPath() {
-	case "/reaction/add":
-		h.handleAdminReactionAdd(event, data)
-	case "/reaction/remove":
-		h.handleAdminReactionRemove(event, data)
-	case "/reaction/list":
-		h.handleAdminReactionList(event)
-	case "/reaction/leaderboard/create":
-		h.handleAdminLeaderboardCreate(event, data)
-	case "/reaction/leaderboard/remove":
-		h.handleAdminLeaderboardRemove(event, data)
-	case "/reaction/leaderboard/list":
-		h.handleAdminLeaderboardList(event)
-	default:
-		_ = respondEphemeralTone(event, EmbedWarn, "Unknown subcommand.")
-	}
-}
-
-func (h *Handler) handleAdminReactionAdd(event *events.ApplicationCommandInteractionCreate, data discord.SlashCommandInteractionData) {
-	if h.reactionTrackStore == nil {
-		_ = respondEphemeralTone(event, EmbedError, "Reaction track store is not configured.")
-		return
-	}
-
+func (h *Handler) ReactionTrackAdd(c *commands.Context) error {
+	event, data := c.Event, c.Data
 	rawEmoji, ok := data.OptString("emoji")
 	if !ok || strings.TrimSpace(rawEmoji) == "" {
 		_ = respondEphemeralTone(event, EmbedWarn, "Emoji is required.")
-		return
+		return nil
 	}
 
 	title, ok := data.OptString("title")
 	title = strings.TrimSpace(title)
 	if !ok || title == "" {
 		_ = respondEphemeralTone(event, EmbedWarn, "Title is required.")
-		return
+		return nil
 	}
 
 	emojiID, emojiName, err := parseEmojiInput(rawEmoji)
 	if err != nil {
 		_ = respondEphemeralTone(event, EmbedWarn, err.Error())
-		return
+		return nil
 	}
 
 	if emojiID != "" {
@@ -77,11 +51,11 @@ func (h *Handler) handleAdminReactionAdd(event *events.ApplicationCommandInterac
 		ok, err := ensureGuildEmoji(event, guildID, emojiID)
 		if err != nil {
 			_ = respondEphemeralTone(event, EmbedWarn, "Couldn't verify that emoji. Make sure it belongs to this server or use a unicode emoji.")
-			return
+			return nil
 		}
 		if !ok {
 			_ = respondEphemeralTone(event, EmbedDecline, "Custom emojis must belong to this server.")
-			return
+			return nil
 		}
 	}
 
@@ -92,65 +66,59 @@ func (h *Handler) handleAdminReactionAdd(event *events.ApplicationCommandInterac
 	created, err := h.reactionTrackStore.UpsertReactionTrack(context.Background(), guildID, emojiID, emojiName, title, description)
 	if err != nil {
 		_ = respondEphemeralTone(event, EmbedError, "Failed to save tracked reaction.")
-		return
+		return nil
 	}
 
 	display := formatEmojiDisplay(emojiID, emojiName)
 	if created {
 		_ = respondEphemeralTone(event, EmbedSuccess, fmt.Sprintf("Tracking %s for reactions.", display))
-		return
+		return nil
 	}
 	_ = respondEphemeralTone(event, EmbedSuccess, fmt.Sprintf("Updated tracking for %s.", display))
+	return nil
 }
 
-func (h *Handler) handleAdminReactionRemove(event *events.ApplicationCommandInteractionCreate, data discord.SlashCommandInteractionData) {
-	if h.reactionTrackStore == nil {
-		_ = respondEphemeralTone(event, EmbedError, "Reaction track store is not configured.")
-		return
-	}
-
+func (h *Handler) ReactionTrackRemove(c *commands.Context) error {
+	event, data := c.Event, c.Data
 	rawEmoji, ok := data.OptString("emoji")
 	if !ok || strings.TrimSpace(rawEmoji) == "" {
 		_ = respondEphemeralTone(event, EmbedWarn, "Emoji is required.")
-		return
+		return nil
 	}
 
 	emojiID, emojiName, err := parseEmojiInput(rawEmoji)
 	if err != nil {
 		_ = respondEphemeralTone(event, EmbedWarn, err.Error())
-		return
+		return nil
 	}
 
 	guildID := *event.GuildID()
 	deleted, err := h.reactionTrackStore.RemoveReactionTrack(context.Background(), guildID, emojiID, emojiName)
 	if err != nil {
 		_ = respondEphemeralTone(event, EmbedError, "Failed to remove tracked reaction.")
-		return
+		return nil
 	}
 
 	display := formatEmojiDisplay(emojiID, emojiName)
 	if deleted == 0 {
 		_ = respondEphemeralTone(event, EmbedInfo, fmt.Sprintf("%s was not being tracked.", display))
-		return
+		return nil
 	}
 	_ = respondEphemeralTone(event, EmbedSuccess, fmt.Sprintf("Stopped tracking %s.", display))
+	return nil
 }
 
-func (h *Handler) handleAdminReactionList(event *events.ApplicationCommandInteractionCreate) {
-	if h.reactionTrackStore == nil {
-		_ = respondEphemeralTone(event, EmbedError, "Reaction track store is not configured.")
-		return
-	}
-
+func (h *Handler) ReactionTrackList(c *commands.Context) error {
+	event := c.Event
 	guildID := *event.GuildID()
 	tracks, err := h.reactionTrackStore.ListReactionTracks(context.Background(), guildID)
 	if err != nil {
 		_ = respondEphemeralTone(event, EmbedError, "Failed to load tracked reactions.")
-		return
+		return nil
 	}
 	if len(tracks) == 0 {
 		_ = respondEphemeralTone(event, EmbedInfo, "No reactions are being tracked.")
-		return
+		return nil
 	}
 
 	lines := make([]string, 0, len(tracks))
@@ -172,41 +140,38 @@ func (h *Handler) handleAdminReactionList(event *events.ApplicationCommandIntera
 		Embeds: []discord.Embed{embed},
 		Flags:  discord.MessageFlagEphemeral,
 	})
+	return nil
 }
 
-func (h *Handler) handleAdminLeaderboardCreate(event *events.ApplicationCommandInteractionCreate, data discord.SlashCommandInteractionData) {
-	if h.staticMessageStore == nil {
-		_ = respondEphemeralTone(event, EmbedError, "Static message store is not configured.")
-		return
-	}
-
+func (h *Handler) LeaderboardCreate(c *commands.Context) error {
+	event, data := c.Event, c.Data
 	channel, ok := data.OptChannel("channel")
 	if !ok {
 		_ = respondEphemeralTone(event, EmbedWarn, "Channel is required.")
-		return
+		return nil
 	}
 	channelID := channel.ID
 
 	rawEmoji, ok := data.OptString("emoji")
 	if !ok || strings.TrimSpace(rawEmoji) == "" {
 		_ = respondEphemeralTone(event, EmbedWarn, "Emoji is required.")
-		return
+		return nil
 	}
 	emojiID, emojiName, err := parseEmojiInput(rawEmoji)
 	if err != nil {
 		_ = respondEphemeralTone(event, EmbedWarn, err.Error())
-		return
+		return nil
 	}
 
 	update, ok := data.OptString("update")
 	update = strings.TrimSpace(strings.ToLower(update))
 	if !ok || update == "" {
 		_ = respondEphemeralTone(event, EmbedWarn, "Update cadence is required.")
-		return
+		return nil
 	}
 	if update != "instant" && update != "hourly" && update != "daily" {
 		_ = respondEphemeralTone(event, EmbedWarn, "Update must be instant, hourly, or daily.")
-		return
+		return nil
 	}
 
 	top := 0
@@ -225,7 +190,7 @@ func (h *Handler) handleAdminLeaderboardCreate(event *events.ApplicationCommandI
 	})
 	if err != nil {
 		_ = respondEphemeralTone(event, EmbedError, "Failed to serialize leaderboard config.")
-		return
+		return nil
 	}
 
 	embed := BuildEmbed(EmbedTemplate{
@@ -239,66 +204,60 @@ func (h *Handler) handleAdminLeaderboardCreate(event *events.ApplicationCommandI
 	})
 	if err != nil {
 		_ = respondEphemeralTone(event, EmbedError, "Failed to create the leaderboard message.")
-		return
+		return nil
 	}
 
 	guildID := *event.GuildID()
 	if err := h.staticMessageStore.CreateStaticMessage(context.Background(), guildID, channelID, message.ID, staticMessageTypeLeaderboard, string(configBytes), update); err != nil {
 		_ = event.Client().Rest().DeleteMessage(channelID, message.ID)
 		_ = respondEphemeralTone(event, EmbedError, "Failed to store the leaderboard message.")
-		return
+		return nil
 	}
 
 	_ = respondEphemeralTone(event, EmbedSuccess, fmt.Sprintf("Leaderboard message created in <#%s>.", channelID.String()))
+	return nil
 }
 
-func (h *Handler) handleAdminLeaderboardRemove(event *events.ApplicationCommandInteractionCreate, data discord.SlashCommandInteractionData) {
-	if h.staticMessageStore == nil {
-		_ = respondEphemeralTone(event, EmbedError, "Static message store is not configured.")
-		return
-	}
-
+func (h *Handler) LeaderboardRemove(c *commands.Context) error {
+	event, data := c.Event, c.Data
 	messageRaw, ok := data.OptString("message_id")
 	messageRaw = strings.TrimSpace(messageRaw)
 	if !ok || messageRaw == "" {
 		_ = respondEphemeralTone(event, EmbedWarn, "Message ID is required.")
-		return
+		return nil
 	}
 
 	messageID, err := snowflake.Parse(messageRaw)
 	if err != nil {
 		_ = respondEphemeralTone(event, EmbedWarn, "Message ID must be a number.")
-		return
+		return nil
 	}
 
 	guildID := *event.GuildID()
 	deleted, err := h.staticMessageStore.RemoveStaticMessage(context.Background(), guildID, messageID)
 	if err != nil {
 		_ = respondEphemeralTone(event, EmbedError, "Failed to remove the leaderboard message.")
-		return
+		return nil
 	}
 	if deleted == 0 {
 		_ = respondEphemeralTone(event, EmbedInfo, "That message was not registered.")
-		return
+		return nil
 	}
 	_ = respondEphemeralTone(event, EmbedSuccess, "Leaderboard message removed.")
+	return nil
 }
 
-func (h *Handler) handleAdminLeaderboardList(event *events.ApplicationCommandInteractionCreate) {
-	if h.staticMessageStore == nil {
-		_ = respondEphemeralTone(event, EmbedError, "Static message store is not configured.")
-		return
-	}
-
+func (h *Handler) LeaderboardList(c *commands.Context) error {
+	event := c.Event
 	guildID := *event.GuildID()
 	messages, err := h.staticMessageStore.ListStaticMessages(context.Background(), guildID)
 	if err != nil {
 		_ = respondEphemeralTone(event, EmbedError, "Failed to load leaderboard messages.")
-		return
+		return nil
 	}
 	if len(messages) == 0 {
 		_ = respondEphemeralTone(event, EmbedInfo, "No leaderboard messages are configured.")
-		return
+		return nil
 	}
 
 	lines := make([]string, 0, len(messages))
@@ -327,6 +286,7 @@ func (h *Handler) handleAdminLeaderboardList(event *events.ApplicationCommandInt
 		Embeds: []discord.Embed{embed},
 		Flags:  discord.MessageFlagEphemeral,
 	})
+	return nil
 }
 
 func parseEmojiInput(raw string) (string, string, error) {
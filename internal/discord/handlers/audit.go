@@ -0,0 +1,340 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"antartica-bot/internal/bus"
+	"antartica-bot/internal/discord/commands"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/events"
+	"github.com/disgoorg/disgo/rest"
+)
+
+const (
+	auditOutcomeSuccess = "success"
+	auditOutcomeInfo    = "info"
+	auditOutcomeDecline = "decline"
+	auditOutcomeWarn    = "warn"
+	auditOutcomeError   = "error"
+	auditOutcomeUnknown = "unknown"
+
+	auditLogCategory = "audit"
+	auditTimeout     = 5 * time.Second
+	maxAuditValueLen = 200
+)
+
+// auditedCommands lists the admin commands recorded in the audit log.
+var auditedCommands = map[string]bool{
+	commands.RoleToggleCommandName:  true,
+	commands.ReactionCommandName:    true,
+	commands.BotCommandName:         true,
+	commands.LogCommandName:         true,
+	commands.ConfigCommandName:      true,
+	commands.PermissionsCommandName: true,
+}
+
+// auditTargetOptions are checked in order to pick the entry's target.
+var auditTargetOptions = []string{"role", "user", "channel", "message_id", "emoji", "name", "key", "command"}
+
+// activeAudits maps the event of each audited command in flight to its recorder, so the respond helpers
+// can record the tone they answered with.
+var activeAudits sync.Map
+
+type auditRecorder struct {
+	handler *Handler
+	entry   bus.AuditEntry
+
+	mu sync.Mutex
+	// captured is set by the first response; toned is set once the outcome comes from an explicit tone,
+	// which then wins over a captured response without one.
+	captured bool
+	toned    bool
+}
+
+// auditMiddleware records audited commands with the reply they ended up sending.
+func (h *Handler) auditMiddleware(next commands.HandlerFunc) commands.HandlerFunc {
+	return func(c *commands.Context) error {
+		audit := h.startAudit(c.Event, c.Data)
+		if audit == nil {
+			return next(c)
+		}
+		defer audit.finish(c.Event)
+
+		err := next(c)
+		if err != nil && !c.Responded() {
+			// The router's error responses run outside this middleware and answer once it returns, so
+			// record the reply they are going to send.
+			var reply *commands.ReplyError
+			if errors.As(err, &reply) {
+				audit.record(reply.Tone, reply.Message)
+			} else {
+				audit.record(EmbedError, "Something went wrong while running this command.")
+			}
+		}
+		return err
+	}
+}
+
+// recordAuditTone records the tone a respond helper answered with, when the command is audited.
+func recordAuditTone(event *events.ApplicationCommandInteractionCreate, tone EmbedTone, message string) {
+	if recorder, ok := activeAudits.Load(event); ok {
+		recorder.(*auditRecorder).record(tone, message)
+	}
+}
+
+// startAudit registers a recorder for the event and wraps its responder, so the first response decides
+// the outcome. Returns nil when the command is not audited.
+func (h *Handler) startAudit(event *events.ApplicationCommandInteractionCreate, data discord.SlashCommandInteractionData) *auditRecorder {
+	if h.auditLogStore == nil || event.GuildID() == nil || !auditedCommands[data.CommandName()] {
+		return nil
+	}
+
+	user := event.User()
+	recorder := &auditRecorder{
+		handler: h,
+		entry: bus.AuditEntry{
+			GuildID:   *event.GuildID(),
+			ActorID:   user.ID,
+			ActorName: user.EffectiveName(),
+			Command:   data.CommandPath(),
+			Options:   auditOptions(data),
+			Target:    auditTarget(data),
+			Outcome:   auditOutcomeUnknown,
+			CreatedAt: time.Now().UTC(),
+		},
+	}
+
+	respond := event.Respond
+	event.Respond = func(responseType discord.InteractionResponseType, response discord.InteractionResponseData, opts ...rest.RequestOpt) error {
+		if responseType == discord.InteractionResponseTypeCreateMessage {
+			recorder.capture(response)
+		}
+		return respond(responseType, response, opts...)
+	}
+	activeAudits.Store(event, recorder)
+
+	return recorder
+}
+
+// record sets the outcome from the tone of the first toned response.
+func (r *auditRecorder) record(tone EmbedTone, message string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.toned {
+		return
+	}
+	r.captured = true
+	r.toned = true
+	r.entry.Outcome = auditOutcomeFromTone(tone)
+	r.entry.Message = truncateAuditValue(message)
+}
+
+// capture covers responses sent without a respond helper. They carry no tone, so they count as info
+// unless a tone is recorded for them.
+func (r *auditRecorder) capture(response discord.InteractionResponseData) {
+	message, ok := response.(discord.MessageCreate)
+	if !ok {
+		return
+	}
+
+	text := message.Content
+	if len(message.Embeds) > 0 {
+		text = message.Embeds[0].Description
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.captured {
+		return
+	}
+	r.captured = true
+	r.entry.Outcome = auditOutcomeInfo
+	r.entry.Message = truncateAuditValue(text)
+}
+
+// finish stores the entry and mirrors it as a log event.
+func (r *auditRecorder) finish(event *events.ApplicationCommandInteractionCreate) {
+	activeAudits.Delete(event)
+
+	r.mu.Lock()
+	entry := r.entry
+	r.mu.Unlock()
+
+	h := r.handler
+	ctx, cancel := context.WithTimeout(context.Background(), auditTimeout)
+	defer cancel()
+	if err := h.auditLogStore.RecordAudit(ctx, entry); err != nil {
+		h.logger.Error("failed to record audit entry", slog.String("command", entry.Command), slog.Any("err", err))
+	}
+
+	if h.bus == nil {
+		return
+	}
+
+	fields := []bus.LogField{
+		{Name: "Actor", Value: fmt.Sprintf("<@%s>", entry.ActorID), Inline: true},
+		{Name: "Outcome", Value: entry.Outcome, Inline: true},
+	}
+	if entry.Target != "" {
+		fields = append(fields, bus.LogField{Name: "Target", Value: entry.Target, Inline: true})
+	}
+	if options := formatAuditOptions(entry.Options); options != "" {
+		fields = append(fields, bus.LogField{Name: "Options", Value: options})
+	}
+
+	_ = h.bus.PublishAction(context.Background(), bus.LogEvent{
+		GuildID:     entry.GuildID,
+		Category:    auditLogCategory,
+		Level:       auditLogLevel(entry.Outcome),
+		Title:       entry.Command,
+		Description: entry.Message,
+		Fields:      fields,
+		Timestamp:   entry.CreatedAt,
+	})
+}
+
+func (h *Handler) AuditList(c *commands.Context) error {
+	event, data := c.Event, c.Data
+	filter := bus.AuditFilter{}
+	if user, ok := data.OptUser("user"); ok {
+		filter.ActorID = &user.ID
+	}
+	if command, ok := data.OptString("command"); ok {
+		filter.Command = strings.TrimSpace(command)
+	}
+	if outcome, ok := data.OptString("outcome"); ok {
+		filter.Outcome = outcome
+	}
+	if limit, ok := data.OptInt("limit"); ok {
+		filter.Limit = limit
+	}
+
+	entries, err := h.auditLogStore.ListAuditEntries(context.Background(), *event.GuildID(), filter)
+	if err != nil {
+		h.logger.Error("failed to load audit entries", slog.Any("err", err))
+		_ = respondEphemeralTone(event, EmbedError, "Failed to load audit entries.")
+		return nil
+	}
+	if len(entries) == 0 {
+		_ = respondEphemeralTone(event, EmbedInfo, "No audit entries match those filters.")
+		return nil
+	}
+
+	lines := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		line := fmt.Sprintf("<t:%d:R> <@%s> `%s` → %s", entry.CreatedAt.Unix(), entry.ActorID, entry.Command, entry.Outcome)
+		if entry.Target != "" {
+			line += fmt.Sprintf(" (%s)", entry.Target)
+		}
+		lines = append(lines, line)
+	}
+
+	embed := BuildEmbed(EmbedTemplate{
+		Tone:        EmbedInfo,
+		Title:       "Audit Log",
+		Description: truncateText(strings.Join(lines, "\n"), 4096),
+	})
+
+	_ = event.CreateMessage(discord.MessageCreate{
+		Embeds: []discord.Embed{embed},
+		Flags:  discord.MessageFlagEphemeral,
+	})
+	return nil
+}
+
+func auditOptions(data discord.SlashCommandInteractionData) map[string]string {
+	options := make(map[string]string, len(data.Options))
+	for name, option := range data.Options {
+		options[name] = formatAuditOption(option)
+	}
+	return options
+}
+
+func formatAuditOption(option discord.SlashCommandOption) string {
+	var value any
+	if err := json.Unmarshal(option.Value, &value); err != nil {
+		return truncateAuditValue(string(option.Value))
+	}
+
+	raw := fmt.Sprint(value)
+	if text, ok := value.(string); ok {
+		raw = text
+	}
+
+	switch option.Type {
+	case discord.ApplicationCommandOptionTypeUser:
+		return fmt.Sprintf("<@%s>", raw)
+	case discord.ApplicationCommandOptionTypeRole:
+		return fmt.Sprintf("<@&%s>", raw)
+	case discord.ApplicationCommandOptionTypeChannel:
+		return fmt.Sprintf("<#%s>", raw)
+	default:
+		return truncateAuditValue(raw)
+	}
+}
+
+func auditTarget(data discord.SlashCommandInteractionData) string {
+	for _, name := range auditTargetOptions {
+		if option, ok := data.Options[name]; ok {
+			return formatAuditOption(option)
+		}
+	}
+	return ""
+}
+
+func formatAuditOptions(options map[string]string) string {
+	if len(options) == 0 {
+		return ""
+	}
+
+	names := make([]string, 0, len(options))
+	for name := range options {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	lines := make([]string, 0, len(names))
+	for _, name := range names {
+		lines = append(lines, fmt.Sprintf("%s: %s", name, options[name]))
+	}
+	return truncateText(strings.Join(lines, "\n"), 1024)
+}
+
+func auditOutcomeFromTone(tone EmbedTone) string {
+	switch tone {
+	case EmbedSuccess:
+		return auditOutcomeSuccess
+	case EmbedDecline:
+		return auditOutcomeDecline
+	case EmbedWarn:
+		return auditOutcomeWarn
+	case EmbedError:
+		return auditOutcomeError
+	default:
+		return auditOutcomeInfo
+	}
+}
+
+func auditLogLevel(outcome string) bus.LogLevel {
+	switch outcome {
+	case auditOutcomeError:
+		return bus.LogError
+	case auditOutcomeDecline, auditOutcomeWarn, auditOutcomeUnknown:
+		return bus.LogWarn
+	default:
+		return bus.LogInfo
+	}
+}
+
+func truncateAuditValue(value string) string {
+	return truncateText(strings.TrimSpace(value), maxAuditValueLen)
+}
@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"antartica-bot/internal/bus"
+	"antartica-bot/internal/discord/commands"
+
+	"github.com/disgoorg/disgo/discord"
+)
+
+func (h *Handler) AutoRoleAdd(c *commands.Context) error {
+	event, data := c.Event, c.Data
+	role, ok := data.OptRole("role")
+	if !ok {
+		_ = respondEphemeralTone(event, EmbedWarn, "Role is required.")
+		return nil
+	}
+
+	mode, _ := data.OptString("mode")
+	mode = strings.TrimSpace(strings.ToLower(mode))
+	if mode != bus.AutoRoleModeJoin && mode != bus.AutoRoleModeSticky {
+		_ = respondEphemeralTone(event, EmbedWarn, "Mode must be join or sticky.")
+		return nil
+	}
+
+	delaySeconds, _ := data.OptInt("delay")
+	if delaySeconds < 0 {
+		_ = respondEphemeralTone(event, EmbedWarn, "Delay can't be negative.")
+		return nil
+	}
+
+	afterScreening, _ := data.OptBool("after_screening")
+	if afterScreening && mode != bus.AutoRoleModeJoin {
+		_ = respondEphemeralTone(event, EmbedWarn, "Membership screening only applies to join roles.")
+		return nil
+	}
+
+	if ok, reason := h.canManageRole(event, role); !ok {
+		_ = respondEphemeralTone(event, EmbedDecline, reason)
+		return nil
+	}
+
+	guildID := *event.GuildID()
+	delay := time.Duration(delaySeconds) * time.Second
+	created, err := h.autoRoleStore.UpsertAutoRole(context.Background(), guildID, role.ID, mode, delay, afterScreening)
+	if err != nil {
+		h.logger.Error("failed to save auto role", slog.Any("err", err))
+		_ = respondEphemeralTone(event, EmbedError, "Failed to save auto role.")
+		return nil
+	}
+
+	if created {
+		_ = respondEphemeralTone(event, EmbedSuccess, fmt.Sprintf("Added %s as a %s role.", role.Mention(), mode))
+		return nil
+	}
+	_ = respondEphemeralTone(event, EmbedSuccess, fmt.Sprintf("Updated %s as a %s role.", role.Mention(), mode))
+	return nil
+}
+
+func (h *Handler) AutoRoleRemove(c *commands.Context) error {
+	event, data := c.Event, c.Data
+	role, ok := data.OptRole("role")
+	if !ok {
+		_ = respondEphemeralTone(event, EmbedWarn, "Role is required.")
+		return nil
+	}
+
+	mode, _ := data.OptString("mode")
+	mode = strings.TrimSpace(strings.ToLower(mode))
+
+	guildID := *event.GuildID()
+	deleted, err := h.autoRoleStore.RemoveAutoRole(context.Background(), guildID, role.ID, mode)
+	if err != nil {
+		h.logger.Error("failed to remove auto role", slog.Any("err", err))
+		_ = respondEphemeralTone(event, EmbedError, "Failed to remove auto role.")
+		return nil
+	}
+
+	if deleted == 0 {
+		_ = respondEphemeralTone(event, EmbedInfo, fmt.Sprintf("%s was not an auto role.", role.Mention()))
+		return nil
+	}
+	_ = respondEphemeralTone(event, EmbedSuccess, fmt.Sprintf("Removed %s from the auto roles.", role.Mention()))
+	return nil
+}
+
+func (h *Handler) AutoRoleList(c *commands.Context) error {
+	event := c.Event
+	guildID := *event.GuildID()
+	autoRoles, err := h.autoRoleStore.ListAutoRoles(context.Background(), guildID)
+	if err != nil {
+		h.logger.Error("failed to load auto roles", slog.Any("err", err))
+		_ = respondEphemeralTone(event, EmbedError, "Failed to load auto roles.")
+		return nil
+	}
+	if len(autoRoles) == 0 {
+		_ = respondEphemeralTone(event, EmbedInfo, "No auto roles are configured.")
+		return nil
+	}
+
+	sort.Slice(autoRoles, func(i, j int) bool {
+		return autoRoles[i].RoleID < autoRoles[j].RoleID
+	})
+
+	joinLines := make([]string, 0)
+	stickyLines := make([]string, 0)
+	for _, autoRole := range autoRoles {
+		line := fmt.Sprintf("<@&%s>", autoRole.RoleID.String())
+		details := make([]string, 0, 2)
+		if autoRole.Delay > 0 {
+			details = append(details, fmt.Sprintf("after %s", autoRole.Delay))
+		}
+		if autoRole.AfterScreening {
+			details = append(details, "after screening")
+		}
+		if len(details) > 0 {
+			line = fmt.Sprintf("%s - %s", line, strings.Join(details, ", "))
+		}
+
+		switch autoRole.Mode {
+		case bus.AutoRoleModeSticky:
+			stickyLines = append(stickyLines, line)
+		default:
+			joinLines = append(joinLines, line)
+		}
+	}
+
+	joinValue := "None"
+	if len(joinLines) > 0 {
+		joinValue = strings.Join(joinLines, "\n")
+	}
+	stickyValue := "None"
+	if len(stickyLines) > 0 {
+		stickyValue = strings.Join(stickyLines, "\n")
+	}
+
+	embed := BuildEmbed(EmbedTemplate{
+		Tone:  EmbedInfo,
+		Title: "Auto Roles",
+		Fields: []discord.EmbedField{
+			{Name: "On join", Value: joinValue},
+			{Name: "Restored on rejoin", Value: stickyValue},
+		},
+	})
+
+	_ = event.CreateMessage(discord.MessageCreate{
+		Embeds: []discord.Embed{embed},
+		Flags:  discord.MessageFlagEphemeral,
+	})
+	return nil
+}
@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/events"
+
+	"antartica-bot/internal/discord/commands"
+)
+
+// maxAutocompleteChoices is the most choices Discord accepts in a single autocomplete response.
+const maxAutocompleteChoices = 25
+
+// maxAutocompleteChoiceName is the longest name Discord accepts for an autocomplete choice.
+const maxAutocompleteChoiceName = 100
+
+func (h *Handler) OnAutocomplete(event *events.AutocompleteInteractionCreate) {
+	switch event.Data.CommandName {
+	case commands.RoleSelfCommandName:
+		h.handleRoleToggleSelfAutocomplete(event)
+	case commands.ConfigCommandName:
+		h.handleConfigAutocomplete(event)
+	case commands.PermissionsCommandName:
+		h.handleCommandPathAutocomplete(event)
+	default:
+		_ = respondAutocomplete(event, nil)
+	}
+}
+
+func respondAutocomplete(event *events.AutocompleteInteractionCreate, choices []discord.AutocompleteChoice) error {
+	if choices == nil {
+		choices = []discord.AutocompleteChoice{}
+	}
+	if len(choices) > maxAutocompleteChoices {
+		choices = choices[:maxAutocompleteChoices]
+	}
+	return event.AutocompleteResult(choices)
+}
+
+func truncateChoiceName(name string) string {
+	return truncateText(name, maxAutocompleteChoiceName)
+}
+
+func truncateText(value string, limit int) string {
+	runes := []rune(value)
+	if len(runes) <= limit {
+		return value
+	}
+	return string(runes[:limit-1]) + "…"
+}
@@ -11,6 +11,8 @@ import (
 	"path"
 	"strings"
 
+	"antartica-bot/internal/discord/commands"
+
 	"github.com/disgoorg/disgo/discord"
 	"github.com/disgoorg/disgo/events"
 	"github.com/disgoorg/disgo/gateway"
@@ -18,53 +20,34 @@ import (
 	"github.com/disgoorg/snowflake/v2"
 )
 
-func (h *Handler) handleBotCommand(event *events.ApplicationCommandInteractionCreate, data discord.SlashCommandInteractionData) {
-	if event.GuildID() == nil {
-		_ = respondEphemeralTone(event, EmbedDecline, "This command can only be used in a server.")
-		return
-	}
-
-	switch data.CommandPath() {
-	case "/bot/name":
-		h.handleBotName(event, data)
-	case "/bot/avatar":
-		h.handleBotAvatar(event, data)
-	case "/bot/banner":
-		h.handleBotBanner(event, data)
-	case "/bot/about":
-		h.handleBotAbout(event, data)
-	case "/bot/status":
-		h.handleBotStatus(event, data)
-	case "/bot/activity":
-		h.handleBotActivity(event, data)
-	default:
-		_ = respondEphemeralTone(event, EmbedWarn, "Unknown subcommand.")
-	}
-}
-
-func (h *Handler) handleBotName(event *events.ApplicationCommandInteractionCreate, data discord.SlashCommandInteractionData) {
+func (h *Handler) BotName(c *commands.Context) error {
+	event, data := c.Event, c.Data
 	name, ok := data.OptString("name")
 	name = strings.TrimSpace(name)
 	if !ok || name == "" {
 		_ = respondEphemeralTone(event, EmbedWarn, "Name is required.")
-		return
+		return nil
 	}
 
 	_, err := event.Client().Rest().UpdateCurrentUser(discord.UserUpdate{Username: name})
 	if err != nil {
 		h.logger.Error("failed to update bot name", slog.Any("err", err))
 		_ = respondEphemeralTone(event, EmbedError, "Failed to update bot name.")
-		return
+		return nil
 	}
 
 	_ = respondEphemeralTone(event, EmbedSuccess, "Bot name updated.")
+	return nil
 }
 
-func (h *Handler) handleBotAvatar(event *events.ApplicationCommandInteractionCreate, data discord.SlashCommandInteractionData) {
+func (h *Handler) BotAvatar(c *commands.Context) error {
+	event, data := c.Event, c.Data
+	// Downloading the image can take longer than Discord waits for a reply.
+	_ = c.Defer()
 	icon, err := resolveBotIcon(event, data)
 	if err != nil {
 		_ = respondEphemeralTone(event, EmbedWarn, err.Error())
-		return
+		return nil
 	}
 
 	_, err = event.Client().Rest().UpdateCurrentUser(discord.UserUpdate{
@@ -73,17 +56,21 @@ func (h *Handler) handleBotAvatar(event *events.ApplicationCommandInteractionCre
 	if err != nil {
 		h.logger.Error("failed to update bot avatar", slog.Any("err", err))
 		_ = respondEphemeralTone(event, EmbedError, "Failed to update bot avatar.")
-		return
+		return nil
 	}
 
 	_ = respondEphemeralTone(event, EmbedSuccess, "Bot avatar updated.")
+	return nil
 }
 
-func (h *Handler) handleBotBanner(event *events.ApplicationCommandInteractionCreate, data discord.SlashCommandInteractionData) {
+func (h *Handler) BotBanner(c *commands.Context) error {
+	event, data := c.Event, c.Data
+	// Downloading the image can take longer than Discord waits for a reply.
+	_ = c.Defer()
 	icon, err := resolveBotIcon(event, data)
 	if err != nil {
 		_ = respondEphemeralTone(event, EmbedWarn, err.Error())
-		return
+		return nil
 	}
 
 	_, err = event.Client().Rest().UpdateCurrentUser(discord.UserUpdate{
@@ -92,18 +79,20 @@ func (h *Handler) handleBotBanner(event *events.ApplicationCommandInteractionCre
 	if err != nil {
 		h.logger.Error("failed to update bot banner", slog.Any("err", err))
 		_ = respondEphemeralTone(event, EmbedError, "Failed to update bot banner.")
-		return
+		return nil
 	}
 
 	_ = respondEphemeralTone(event, EmbedSuccess, "Bot banner updated.")
+	return nil
 }
 
-func (h *Handler) handleBotAbout(event *events.ApplicationCommandInteractionCreate, data discord.SlashCommandInteractionData) {
+func (h *Handler) BotAbout(c *commands.Context) error {
+	event, data := c.Event, c.Data
 	about, ok := data.OptString("text")
 	about = strings.TrimSpace(about)
 	if !ok || about == "" {
 		_ = respondEphemeralTone(event, EmbedWarn, "About text is required.")
-		return
+		return nil
 	}
 
 	_, err := event.Client().Rest().UpdateCurrentApplication(discord.ApplicationUpdate{
@@ -112,13 +101,15 @@ func (h *Handler) handleBotAbout(event *events.ApplicationCommandInteractionCrea
 	if err != nil {
 		h.logger.Error("failed to update bot description", slog.Any("err", err))
 		_ = respondEphemeralTone(event, EmbedError, "Failed to update bot description.")
-		return
+		return nil
 	}
 
 	_ = respondEphemeralTone(event, EmbedSuccess, "Bot description updated.")
+	return nil
 }
 
-func (h *Handler) handleBotStatus(event *events.ApplicationCommandInteractionCreate, data discord.SlashCommandInteractionData) {
+func (h *Handler) BotStatus(c *commands.Context) error {
+	event, data := c.Event, c.Data
 	raw, _ := data.OptString("status")
 	raw = strings.TrimSpace(strings.ToLower(raw))
 	status := discord.OnlineStatusOnline
@@ -126,7 +117,7 @@ func (h *Handler) handleBotStatus(event *events.ApplicationCommandInteractionCre
 		parsed, ok := parseOnlineStatus(raw)
 		if !ok {
 			_ = respondEphemeralTone(event, EmbedWarn, "Status must be online, idle, dnd, invisible, or offline.")
-			return
+			return nil
 		}
 		status = parsed
 	}
@@ -134,13 +125,15 @@ func (h *Handler) handleBotStatus(event *events.ApplicationCommandInteractionCre
 	if err := event.Client().SetPresence(context.Background(), gateway.WithOnlineStatus(status)); err != nil {
 		h.logger.Error("failed to update bot status", slog.Any("err", err))
 		_ = respondEphemeralTone(event, EmbedError, "Failed to update bot status.")
-		return
+		return nil
 	}
 
 	_ = respondEphemeralTone(event, EmbedSuccess, "Bot status updated.")
+	return nil
 }
 
-func (h *Handler) handleBotActivity(event *events.ApplicationCommandInteractionCreate, data discord.SlashCommandInteractionData) {
+func (h *Handler) BotActivity(c *commands.Context) error {
+	event, data := c.Event, c.Data
 	typeRaw, _ := data.OptString("type")
 	typeRaw = strings.TrimSpace(strings.ToLower(typeRaw))
 	text, _ := data.OptString("text")
@@ -152,20 +145,20 @@ func (h *Handler) handleBotActivity(event *events.ApplicationCommandInteractionC
 		if err := event.Client().SetPresence(context.Background(), clearActivityPresence()); err != nil {
 			h.logger.Error("failed to clear bot activity", slog.Any("err", err))
 			_ = respondEphemeralTone(event, EmbedError, "Failed to clear bot activity.")
-			return
+			return nil
 		}
 		_ = respondEphemeralTone(event, EmbedSuccess, "Bot activity cleared.")
-		return
+		return nil
 	}
 
 	if typeRaw == "" {
 		_ = respondEphemeralTone(event, EmbedWarn, "Activity type is required when setting activity.")
-		return
+		return nil
 	}
 
 	if emojiRaw != "" && typeRaw != "custom" {
 		_ = respondEphemeralTone(event, EmbedWarn, "Emoji is only supported for custom activity.")
-		return
+		return nil
 	}
 
 	var opt gateway.PresenceOpt
@@ -188,23 +181,24 @@ func (h *Handler) handleBotActivity(event *events.ApplicationCommandInteractionC
 			emoji, err := parseActivityEmoji(emojiRaw)
 			if err != nil {
 				_ = respondEphemeralTone(event, EmbedWarn, err.Error())
-				return
+				return nil
 			}
 			activity.Emoji = emoji
 		}
 		opt = presenceWithActivity(activity)
 	default:
 		_ = respondEphemeralTone(event, EmbedWarn, "Activity type must be playing, listening, watching, competing, or custom.")
-		return
+		return nil
 	}
 
 	if err := event.Client().SetPresence(context.Background(), opt); err != nil {
 		h.logger.Error("failed to update bot activity", slog.Any("err", err))
 		_ = respondEphemeralTone(event, EmbedError, "Failed to update bot activity.")
-		return
+		return nil
 	}
 
 	_ = respondEphemeralTone(event, EmbedSuccess, "Bot activity updated.")
+	return nil
 }
 
 func resolveBotIcon(event *events.ApplicationCommandInteractionCreate, data discord.SlashCommandInteractionData) (*discord.Icon, error) {
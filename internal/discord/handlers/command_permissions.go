@@ -0,0 +1,336 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+
+	"antartica-bot/internal/bus"
+	"antartica-bot/internal/discord/commands"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/events"
+	"github.com/disgoorg/snowflake/v2"
+)
+
+// permissionsPath can't be disabled, so a server can't lock itself out of undoing it.
+const permissionsPath = "/" + commands.PermissionsCommandName
+
+// commandAccessMiddleware enforces the guild's command rules and the command's default permissions on the
+// server, since Discord only hides commands in its client. Members with a role granted for the path, or a
+// path above it, may use it without the default permissions. If the rules can't be loaded, only the
+// defaults apply.
+func (h *Handler) commandAccessMiddleware(next commands.HandlerFunc) commands.HandlerFunc {
+	return func(c *commands.Context) error {
+		guildID := c.GuildID()
+		if guildID == 0 {
+			return next(c)
+		}
+
+		access := resolveCommandAccess(c.Path(), h.commandRules(guildID))
+		if access.disabledBy != "" && !isPermissionsPath(c.Path()) {
+			return commands.Reply(EmbedDecline, "%s is disabled in this server.", formatCommandPath(access.disabledBy))
+		}
+
+		required, ok := h.requiredPermissions(c.Path())
+		if !ok || c.MemberPermissions().Has(required) {
+			return next(c)
+		}
+		// Grants stored before a path became ungrantable are ignored rather than trusted.
+		if grantable(c.Path(), required) && access.granted(c.Event.Member()) {
+			return next(c)
+		}
+		return commands.Reply(EmbedDecline, "You don't have access to %s.", formatCommandPath(c.Path()))
+	}
+}
+
+// requiredPermissions returns the default member permissions of the command path belongs to, and false when
+// everyone may use it.
+func (h *Handler) requiredPermissions(path string) (discord.Permissions, bool) {
+	required, ok := h.router.DefaultPermissions(path)
+	if ok && required == 0 {
+		// Discord reads 0 as "administrators only".
+		required = discord.PermissionAdministrator
+	}
+	return required, ok
+}
+
+// grantable reports whether roles may be granted path. Administrator-only commands such as /bot and
+// /permissions itself stay with the permissions Discord checks, so a grant can't hand them out.
+func grantable(path string, required discord.Permissions) bool {
+	return !isPermissionsPath(path) && !required.Has(discord.PermissionAdministrator)
+}
+
+func (h *Handler) commandRules(guildID snowflake.ID) []bus.CommandRule {
+	if h.commandPermissionStore == nil {
+		return nil
+	}
+	rules, err := h.commandPermissionStore.CommandRules(context.Background(), guildID)
+	if err != nil {
+		h.logger.Warn("failed to load command rules", slog.String("guild_id", guildID.String()), slog.Any("err", err))
+		return nil
+	}
+	return rules
+}
+
+// commandAccess is what a guild's rules say about one command path.
+type commandAccess struct {
+	// disabledBy is the path of the rule that turned the command off, empty while it is enabled.
+	disabledBy string
+	roles      map[snowflake.ID]bool
+}
+
+// resolveCommandAccess applies every rule for path or a path above it.
+func resolveCommandAccess(path string, rules []bus.CommandRule) commandAccess {
+	access := commandAccess{roles: make(map[snowflake.ID]bool)}
+	for _, rule := range rules {
+		if rule.Path != path && !strings.HasPrefix(path, rule.Path+"/") {
+			continue
+		}
+		switch rule.Kind {
+		case bus.CommandRuleDisabled:
+			if access.disabledBy == "" || len(rule.Path) < len(access.disabledBy) {
+				access.disabledBy = rule.Path
+			}
+		case bus.CommandRuleRole:
+			access.roles[rule.RoleID] = true
+		}
+	}
+	return access
+}
+
+func (a commandAccess) granted(member *discord.ResolvedMember) bool {
+	if member == nil {
+		return false
+	}
+	for _, roleID := range member.RoleIDs {
+		if a.roles[roleID] {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *Handler) CommandEnable(c *commands.Context) error {
+	event := c.Event
+	path, err := h.commandPathOption(c)
+	if err != nil {
+		return err
+	}
+
+	guildID := *event.GuildID()
+	removed, err := h.commandPermissionStore.RemoveCommandRule(context.Background(), guildID, bus.CommandRule{Path: path, Kind: bus.CommandRuleDisabled})
+	if err != nil {
+		h.logger.Error("failed to enable command", slog.String("path", path), slog.Any("err", err))
+		_ = respondEphemeralTone(event, EmbedError, "Failed to enable command.")
+		return nil
+	}
+
+	if access := resolveCommandAccess(path, h.commandRules(guildID)); access.disabledBy != "" {
+		_ = respondEphemeralTone(event, EmbedWarn, fmt.Sprintf("%s is still disabled by %s.", formatCommandPath(path), formatCommandPath(access.disabledBy)))
+		return nil
+	}
+	if !removed {
+		_ = respondEphemeralTone(event, EmbedInfo, fmt.Sprintf("%s is already enabled.", formatCommandPath(path)))
+		return nil
+	}
+	_ = respondEphemeralTone(event, EmbedSuccess, fmt.Sprintf("Enabled %s.", formatCommandPath(path)))
+	return nil
+}
+
+func (h *Handler) CommandDisable(c *commands.Context) error {
+	event := c.Event
+	path, err := h.commandPathOption(c)
+	if err != nil {
+		return err
+	}
+	if isPermissionsPath(path) {
+		return commands.Reply(EmbedDecline, "%s can't be disabled.", formatCommandPath(permissionsPath))
+	}
+
+	created, err := h.commandPermissionStore.AddCommandRule(context.Background(), *event.GuildID(), bus.CommandRule{Path: path, Kind: bus.CommandRuleDisabled}, event.User().ID)
+	if err != nil {
+		h.logger.Error("failed to disable command", slog.String("path", path), slog.Any("err", err))
+		_ = respondEphemeralTone(event, EmbedError, "Failed to disable command.")
+		return nil
+	}
+
+	if !created {
+		_ = respondEphemeralTone(event, EmbedInfo, fmt.Sprintf("%s is already disabled.", formatCommandPath(path)))
+		return nil
+	}
+	_ = respondEphemeralTone(event, EmbedSuccess, fmt.Sprintf("Disabled %s.", formatCommandPath(path)))
+	return nil
+}
+
+func (h *Handler) CommandAllow(c *commands.Context) error {
+	event, data := c.Event, c.Data
+	path, err := h.commandPathOption(c)
+	if err != nil {
+		return err
+	}
+	role, ok := data.OptRole("role")
+	if !ok {
+		return commands.Reply(EmbedWarn, "Role is required.")
+	}
+
+	// A grant can't give out more than the member granting it holds.
+	if required, ok := h.requiredPermissions(path); ok {
+		if !grantable(path, required) {
+			return commands.Reply(EmbedDecline, "%s can't be granted to roles.", formatCommandPath(path))
+		}
+		if !c.MemberPermissions().Has(required) {
+			return commands.Reply(EmbedDecline, "You can only grant commands you could use without a grant.")
+		}
+	}
+
+	rule := bus.CommandRule{Path: path, Kind: bus.CommandRuleRole, RoleID: role.ID}
+	created, err := h.commandPermissionStore.AddCommandRule(context.Background(), *event.GuildID(), rule, event.User().ID)
+	if err != nil {
+		h.logger.Error("failed to grant command", slog.String("path", path), slog.Any("err", err))
+		_ = respondEphemeralTone(event, EmbedError, "Failed to grant access.")
+		return nil
+	}
+
+	if !created {
+		_ = respondEphemeralTone(event, EmbedInfo, fmt.Sprintf("%s can already use %s.", role.Mention(), formatCommandPath(path)))
+		return nil
+	}
+	_ = respondEphemeralTone(event, EmbedSuccess, fmt.Sprintf("%s can now use %s.", role.Mention(), formatCommandPath(path)))
+	return nil
+}
+
+func (h *Handler) CommandRevoke(c *commands.Context) error {
+	event, data := c.Event, c.Data
+	path, err := h.commandPathOption(c)
+	if err != nil {
+		return err
+	}
+	role, ok := data.OptRole("role")
+	if !ok {
+		return commands.Reply(EmbedWarn, "Role is required.")
+	}
+
+	rule := bus.CommandRule{Path: path, Kind: bus.CommandRuleRole, RoleID: role.ID}
+	removed, err := h.commandPermissionStore.RemoveCommandRule(context.Background(), *event.GuildID(), rule)
+	if err != nil {
+		h.logger.Error("failed to revoke command", slog.String("path", path), slog.Any("err", err))
+		_ = respondEphemeralTone(event, EmbedError, "Failed to revoke access.")
+		return nil
+	}
+
+	if !removed {
+		_ = respondEphemeralTone(event, EmbedInfo, fmt.Sprintf("%s had no access to %s.", role.Mention(), formatCommandPath(path)))
+		return nil
+	}
+	_ = respondEphemeralTone(event, EmbedSuccess, fmt.Sprintf("%s can no longer use %s.", role.Mention(), formatCommandPath(path)))
+	return nil
+}
+
+func (h *Handler) CommandRuleList(c *commands.Context) error {
+	event := c.Event
+	rules, err := h.commandPermissionStore.CommandRules(context.Background(), *event.GuildID())
+	if err != nil {
+		h.logger.Error("failed to list command rules", slog.Any("err", err))
+		_ = respondEphemeralTone(event, EmbedError, "Failed to load command permissions.")
+		return nil
+	}
+	if len(rules) == 0 {
+		_ = respondEphemeralTone(event, EmbedInfo, "Every command is enabled and uses its default permissions.")
+		return nil
+	}
+
+	var disabled []string
+	grants := make(map[string][]string)
+	for _, rule := range rules {
+		switch rule.Kind {
+		case bus.CommandRuleDisabled:
+			disabled = append(disabled, formatCommandPath(rule.Path))
+		case bus.CommandRuleRole:
+			grants[rule.Path] = append(grants[rule.Path], discord.RoleMention(rule.RoleID))
+		}
+	}
+	sort.Strings(disabled)
+
+	grantPaths := make([]string, 0, len(grants))
+	for path := range grants {
+		grantPaths = append(grantPaths, path)
+	}
+	sort.Strings(grantPaths)
+	grantLines := make([]string, 0, len(grantPaths))
+	for _, path := range grantPaths {
+		grantLines = append(grantLines, fmt.Sprintf("%s: %s", formatCommandPath(path), strings.Join(grants[path], ", ")))
+	}
+
+	disabledValue := "None"
+	if len(disabled) > 0 {
+		disabledValue = strings.Join(disabled, "\n")
+	}
+	grantValue := "None"
+	if len(grantLines) > 0 {
+		grantValue = strings.Join(grantLines, "\n")
+	}
+
+	embed := BuildEmbed(EmbedTemplate{
+		Tone:  EmbedInfo,
+		Title: "Command Permissions",
+		Fields: []discord.EmbedField{
+			{Name: "Disabled", Value: disabledValue},
+			{Name: "Role access", Value: grantValue},
+		},
+	})
+
+	_ = event.CreateMessage(discord.MessageCreate{
+		Embeds: []discord.Embed{embed},
+		Flags:  discord.MessageFlagEphemeral,
+	})
+	return nil
+}
+
+// commandPathOption reads the command option and checks it names a known command, group or subcommand.
+func (h *Handler) commandPathOption(c *commands.Context) (string, error) {
+	raw, _ := c.Data.OptString("command")
+	path := normalizeCommandPath(raw)
+	for _, known := range h.router.Paths() {
+		if known == path {
+			return path, nil
+		}
+	}
+	return "", commands.Reply(EmbedWarn, "Unknown command `%s`.", strings.TrimSpace(raw))
+}
+
+// normalizeCommandPath accepts /role auto add, role/auto/add and similar and returns /role/auto/add.
+func normalizeCommandPath(raw string) string {
+	fields := strings.FieldsFunc(strings.ToLower(raw), func(r rune) bool {
+		return r == '/' || r == ' '
+	})
+	return "/" + strings.Join(fields, "/")
+}
+
+func formatCommandPath(path string) string {
+	return "`/" + strings.ReplaceAll(strings.TrimPrefix(path, "/"), "/", " ") + "`"
+}
+
+func isPermissionsPath(path string) bool {
+	return path == permissionsPath || strings.HasPrefix(path, permissionsPath+"/")
+}
+
+// handleCommandPathAutocomplete suggests command paths matching what has been typed.
+func (h *Handler) handleCommandPathAutocomplete(event *events.AutocompleteInteractionCreate) {
+	query := strings.TrimPrefix(normalizeCommandPath(event.Data.String("command")), "/")
+
+	choices := make([]discord.AutocompleteChoice, 0)
+	for _, path := range h.router.Paths() {
+		if query != "" && !strings.Contains(path, query) {
+			continue
+		}
+		choices = append(choices, discord.AutocompleteChoiceString{
+			Name:  truncateChoiceName("/" + strings.ReplaceAll(strings.TrimPrefix(path, "/"), "/", " ")),
+			Value: path,
+		})
+	}
+
+	_ = respondAutocomplete(event, choices)
+}
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"antartica-bot/internal/discord/commands"
+	"antartica-bot/internal/settings"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/events"
+	"github.com/disgoorg/snowflake/v2"
+)
+
+func (h *Handler) ConfigGet(c *commands.Context) error {
+	event, data := c.Event, c.Data
+	keys := settings.Keys()
+	if name, ok := data.OptString("key"); ok && strings.TrimSpace(name) != "" {
+		key, known := settings.Lookup(name)
+		if !known {
+			_ = respondEphemeralTone(event, EmbedWarn, fmt.Sprintf("Unknown setting `%s`.", strings.TrimSpace(name)))
+			return nil
+		}
+		keys = []settings.Key{key}
+	}
+
+	guild, err := settings.Load(context.Background(), h.guildSettingsStore, *event.GuildID())
+	if err != nil {
+		h.logger.Error("failed to load guild settings", slog.Any("err", err))
+		_ = respondEphemeralTone(event, EmbedError, "Failed to load settings.")
+		return nil
+	}
+
+	fields := make([]discord.EmbedField, 0, len(keys))
+	for _, key := range keys {
+		value := key.Display(guild.Get(key.Name))
+		if !guild.IsSet(key.Name) {
+			value += " (default)"
+		}
+		fields = append(fields, discord.EmbedField{
+			Name:  key.Name,
+			Value: fmt.Sprintf("%s\n%s", value, key.Description),
+		})
+	}
+
+	embed := BuildEmbed(EmbedTemplate{
+		Tone:   EmbedInfo,
+		Title:  "Settings",
+		Fields: fields,
+	})
+
+	_ = event.CreateMessage(discord.MessageCreate{
+		Embeds: []discord.Embed{embed},
+		Flags:  discord.MessageFlagEphemeral,
+	})
+	return nil
+}
+
+func (h *Handler) ConfigSet(c *commands.Context) error {
+	event, data := c.Event, c.Data
+	name, _ := data.OptString("key")
+	key, known := settings.Lookup(name)
+	if !known {
+		_ = respondEphemeralTone(event, EmbedWarn, fmt.Sprintf("Unknown setting `%s`.", strings.TrimSpace(name)))
+		return nil
+	}
+
+	raw, _ := data.OptString("value")
+	value, err := key.Normalize(raw)
+	if err != nil {
+		_ = respondEphemeralTone(event, EmbedWarn, err.Error()+".")
+		return nil
+	}
+
+	if key.Kind == settings.KindChannel {
+		// Normalize has already checked the ID.
+		channelID, _ := snowflake.Parse(value)
+		if channel, ok := event.Client().Caches().Channel(channelID); !ok || channel.GuildID() != *event.GuildID() {
+			return commands.Reply(EmbedWarn, "%s must be a channel in this server.", key.Name)
+		}
+	}
+
+	if err := h.guildSettingsStore.SetGuildSetting(context.Background(), *event.GuildID(), key.Name, value, event.User().ID); err != nil {
+		h.logger.Error("failed to save guild setting", slog.String("key", key.Name), slog.Any("err", err))
+		_ = respondEphemeralTone(event, EmbedError, "Failed to save setting.")
+		return nil
+	}
+
+	_ = respondEphemeralTone(event, EmbedSuccess, fmt.Sprintf("Set `%s` to %s.", key.Name, key.Display(value)))
+	return nil
+}
+
+func (h *Handler) ConfigReset(c *commands.Context) error {
+	event, data := c.Event, c.Data
+	name, _ := data.OptString("key")
+	key, known := settings.Lookup(name)
+	if !known {
+		_ = respondEphemeralTone(event, EmbedWarn, fmt.Sprintf("Unknown setting `%s`.", strings.TrimSpace(name)))
+		return nil
+	}
+
+	removed, err := h.guildSettingsStore.ResetGuildSetting(context.Background(), *event.GuildID(), key.Name)
+	if err != nil {
+		h.logger.Error("failed to reset guild setting", slog.String("key", key.Name), slog.Any("err", err))
+		_ = respondEphemeralTone(event, EmbedError, "Failed to reset setting.")
+		return nil
+	}
+
+	if !removed {
+		_ = respondEphemeralTone(event, EmbedInfo, fmt.Sprintf("`%s` is already using its default.", key.Name))
+		return nil
+	}
+	_ = respondEphemeralTone(event, EmbedSuccess, fmt.Sprintf("Reset `%s` to its default (%s).", key.Name, key.Display(key.Default)))
+	return nil
+}
+
+// handleConfigAutocomplete suggests known keys matching what has been typed, by name or description.
+func (h *Handler) handleConfigAutocomplete(event *events.AutocompleteInteractionCreate) {
+	query := strings.ToLower(strings.TrimSpace(event.Data.String("key")))
+
+	choices := make([]discord.AutocompleteChoice, 0)
+	for _, key := range settings.Keys() {
+		if query != "" && !strings.Contains(key.Name, query) && !strings.Contains(strings.ToLower(key.Description), query) {
+			continue
+		}
+		choices = append(choices, discord.AutocompleteChoiceString{
+			Name:  truncateChoiceName(fmt.Sprintf("%s: %s", key.Name, key.Description)),
+			Value: key.Name,
+		})
+	}
+
+	_ = respondAutocomplete(event, choices)
+}
@@ -30,7 +30,7 @@ func (h *Handler) OnGuildMessageReactionAdd(event *events.GuildMessageReactionAd
 
 	authorID := h.resolveMessageAuthorID(event.ChannelID, event.MessageID)
 
-	h.bus.DiscordEvents <- bus.ReactionAdded{
+	_ = h.bus.PublishEvent(context.Background(), bus.ReactionAdded{
 		GuildID:   event.GuildID,
 		ChannelID: event.ChannelID,
 		MessageID: event.MessageID,
@@ -38,7 +38,7 @@ func (h *Handler) OnGuildMessageReactionAdd(event *events.GuildMessageReactionAd
 		AuthorID:  authorID,
 		EmojiName: emojiName,
 		EmojiID:   event.Emoji.ID,
-	}
+	})
 }
 
 func (h *Handler) OnInteractionCreate(event *events.InteractionCreate) {
@@ -47,11 +47,11 @@ func (h *Handler) OnInteractionCreate(event *events.InteractionCreate) {
 	}
 
 	interaction := event.Interaction
-	h.bus.DiscordEvents <- bus.InteractionReceived{
+	_ = h.bus.PublishEvent(context.Background(), bus.InteractionReceived{
 		InteractionID:   interaction.ID(),
 		InteractionType: interaction.Type(),
 		GuildID:         interaction.GuildID(),
 		ChannelID:       interaction.ChannelID(),
 		UserID:          interaction.User().ID,
-	}
+	})
 }
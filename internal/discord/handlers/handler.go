@@ -3,8 +3,10 @@ package handlers
 import (
 	"log/slog"
 	"sync"
+	"sync/atomic"
 
 	"antartica-bot/internal/bus"
+	"antartica-bot/internal/discord/commands"
 
 	"github.com/disgoorg/disgo/bot"
 	"github.com/disgoorg/snowflake/v2"
@@ -15,26 +17,72 @@ type Handler struct {
 	bus    *bus.Bus
 	logger *slog.Logger
 
-	roleToggleStore    bus.RoleToggleStore
-	reactionTrackStore bus.ReactionTrackStore
-	staticMessageStore bus.StaticMessageStore
+	roleToggleStore        bus.RoleToggleStore
+	reactionTrackStore     bus.ReactionTrackStore
+	staticMessageStore     bus.StaticMessageStore
+	autoRoleStore          bus.AutoRoleStore
+	logRouteStore          bus.LogRouteStore
+	auditLogStore          bus.AuditLogStore
+	guildSettingsStore     bus.GuildSettingsStore
+	commandPermissionStore bus.CommandPermissionStore
+
+	router *commands.Router
+
+	// messageCache is nil unless the message log was enabled at startup.
+	messageCache *messageCache
+	// messageLogPaused turns the message log off at runtime without giving up the intents it needs.
+	messageLogPaused atomic.Bool
 
 	botUserCache   map[snowflake.ID]bool
 	botUserCacheMu sync.RWMutex
 }
 
-func New(client bot.Client, eventBus *bus.Bus, logger *slog.Logger, roleToggleStore bus.RoleToggleStore, reactionTrackStore bus.ReactionTrackStore, staticMessageStore bus.StaticMessageStore) *Handler {
+func New(client bot.Client, eventBus *bus.Bus, logger *slog.Logger, roleToggleStore bus.RoleToggleStore, reactionTrackStore bus.ReactionTrackStore, staticMessageStore bus.StaticMessageStore, autoRoleStore bus.AutoRoleStore, logRouteStore bus.LogRouteStore, auditLogStore bus.AuditLogStore, guildSettingsStore bus.GuildSettingsStore, commandPermissionStore bus.CommandPermissionStore) *Handler {
 	if logger == nil {
 		logger = slog.Default()
 	}
 
-	return &Handler{
-		client:             client,
-		bus:                eventBus,
-		logger:             logger,
-		roleToggleStore:    roleToggleStore,
-		reactionTrackStore: reactionTrackStore,
-		staticMessageStore: staticMessageStore,
-		botUserCache:       make(map[snowflake.ID]bool),
+	h := &Handler{
+		client:                 client,
+		bus:                    eventBus,
+		logger:                 logger,
+		roleToggleStore:        roleToggleStore,
+		reactionTrackStore:     reactionTrackStore,
+		staticMessageStore:     staticMessageStore,
+		autoRoleStore:          autoRoleStore,
+		logRouteStore:          logRouteStore,
+		auditLogStore:          auditLogStore,
+		guildSettingsStore:     guildSettingsStore,
+		commandPermissionStore: commandPermissionStore,
+		botUserCache:           make(map[snowflake.ID]bool),
+	}
+	h.router = commands.NewRouter(h, commands.RouterOptions{
+		Logger:     logger,
+		Configured: h.configured,
+		Middleware: []commands.Middleware{h.auditMiddleware, h.commandAccessMiddleware},
+	})
+	return h
+}
+
+// configured reports whether the store a command route requires was provided.
+func (h *Handler) configured(dependency string) bool {
+	switch dependency {
+	case commands.NeedsRoleToggles:
+		return h.roleToggleStore != nil
+	case commands.NeedsReactionTracks:
+		return h.reactionTrackStore != nil
+	case commands.NeedsStaticMessages:
+		return h.staticMessageStore != nil
+	case commands.NeedsAutoRoles:
+		return h.autoRoleStore != nil
+	case commands.NeedsLogRoutes:
+		return h.logRouteStore != nil
+	case commands.NeedsAuditLog:
+		return h.auditLogStore != nil
+	case commands.NeedsGuildSettings:
+		return h.guildSettingsStore != nil
+	case commands.NeedsCommandPermissions:
+		return h.commandPermissionStore != nil
 	}
+	return true
 }
@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"antartica-bot/internal/bus"
+	"antartica-bot/internal/discord/commands"
+
+	"github.com/disgoorg/disgo/discord"
+)
+
+func (h *Handler) LogRouteAdd(c *commands.Context) error {
+	event, data := c.Event, c.Data
+	channel, ok := data.OptChannel("channel")
+	if !ok {
+		_ = respondEphemeralTone(event, EmbedWarn, "Channel is required.")
+		return nil
+	}
+
+	category, _ := data.OptString("category")
+	category = strings.ToLower(strings.TrimSpace(category))
+
+	levelRaw, _ := data.OptString("level")
+	level := bus.LogLevel(strings.ToLower(strings.TrimSpace(levelRaw)))
+	if level == "" {
+		level = bus.LogInfo
+	}
+
+	guildID := *event.GuildID()
+	created, err := h.logRouteStore.UpsertLogRoute(context.Background(), guildID, channel.ID, category, level)
+	if err != nil {
+		h.logger.Error("failed to save log route", slog.Any("err", err))
+		_ = respondEphemeralTone(event, EmbedError, "Failed to save log route.")
+		return nil
+	}
+
+	display := formatLogRoute(channel.ID.String(), category, level)
+	if created {
+		_ = respondEphemeralTone(event, EmbedSuccess, fmt.Sprintf("Logging %s.", display))
+		return nil
+	}
+	_ = respondEphemeralTone(event, EmbedSuccess, fmt.Sprintf("Updated logging to %s.", display))
+	return nil
+}
+
+func (h *Handler) LogRouteRemove(c *commands.Context) error {
+	event, data := c.Event, c.Data
+	channel, ok := data.OptChannel("channel")
+	if !ok {
+		_ = respondEphemeralTone(event, EmbedWarn, "Channel is required.")
+		return nil
+	}
+
+	category, _ := data.OptString("category")
+	category = strings.ToLower(strings.TrimSpace(category))
+
+	guildID := *event.GuildID()
+	deleted, err := h.logRouteStore.RemoveLogRoute(context.Background(), guildID, channel.ID, category)
+	if err != nil {
+		h.logger.Error("failed to remove log route", slog.Any("err", err))
+		_ = respondEphemeralTone(event, EmbedError, "Failed to remove log route.")
+		return nil
+	}
+
+	if deleted == 0 {
+		_ = respondEphemeralTone(event, EmbedInfo, "That log route was not configured.")
+		return nil
+	}
+	if category != "" {
+		_ = respondEphemeralTone(event, EmbedSuccess, fmt.Sprintf("Stopped logging %s to <#%s>.", category, channel.ID.String()))
+		return nil
+	}
+	_ = respondEphemeralTone(event, EmbedSuccess, fmt.Sprintf("Stopped all logging to <#%s> (%d routes).", channel.ID.String(), deleted))
+	return nil
+}
+
+func (h *Handler) LogRouteList(c *commands.Context) error {
+	event := c.Event
+	guildID := *event.GuildID()
+	routes, err := h.logRouteStore.ListLogRoutes(context.Background(), guildID)
+	if err != nil {
+		h.logger.Error("failed to load log routes", slog.Any("err", err))
+		_ = respondEphemeralTone(event, EmbedError, "Failed to load log routes.")
+		return nil
+	}
+	if len(routes) == 0 {
+		_ = respondEphemeralTone(event, EmbedInfo, "No log routes are configured.")
+		return nil
+	}
+
+	lines := make([]string, 0, len(routes))
+	for _, route := range routes {
+		lines = append(lines, formatLogRoute(route.ChannelID.String(), route.Category, route.MinLevel))
+	}
+
+	embed := BuildEmbed(EmbedTemplate{
+		Tone:        EmbedInfo,
+		Title:       "Log Routes",
+		Description: strings.Join(lines, "\n"),
+	})
+
+	_ = event.CreateMessage(discord.MessageCreate{
+		Embeds: []discord.Embed{embed},
+		Flags:  discord.MessageFlagEphemeral,
+	})
+	return nil
+}
+
+func formatLogRoute(channelID string, category string, level bus.LogLevel) string {
+	category = strings.TrimSpace(category)
+	if category == "" {
+		category = "all categories"
+	}
+	return fmt.Sprintf("%s at %s and above to <#%s>", category, level, channelID)
+}
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"antartica-bot/internal/bus"
+	"context"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/events"
+	"github.com/disgoorg/snowflake/v2"
+)
+
+func (h *Handler) OnGuildMemberJoin(event *events.GuildMemberJoin) {
+	if h.bus == nil {
+		return
+	}
+
+	_ = h.bus.PublishEvent(context.Background(), bus.MemberJoined{
+		GuildID:          event.GuildID,
+		UserID:           event.Member.User.ID,
+		Username:         event.Member.User.Username,
+		Bot:              event.Member.User.Bot,
+		Pending:          event.Member.Pending,
+		JoinedAt:         event.Member.JoinedAt,
+		AccountCreatedAt: event.Member.User.ID.Time(),
+	})
+}
+
+func (h *Handler) OnGuildMemberUpdate(event *events.GuildMemberUpdate) {
+	if h.bus == nil {
+		return
+	}
+
+	_ = h.bus.PublishEvent(context.Background(), bus.MemberUpdated{
+		GuildID:        event.GuildID,
+		UserID:         event.Member.User.ID,
+		Username:       event.Member.User.Username,
+		Bot:            event.Member.User.Bot,
+		Pending:        event.Member.Pending,
+		WasPending:     event.OldMember.Pending,
+		AddedRoleIDs:   roleDifference(event.Member.RoleIDs, event.OldMember.RoleIDs),
+		RemovedRoleIDs: roleDifference(event.OldMember.RoleIDs, event.Member.RoleIDs),
+	})
+}
+
+func (h *Handler) OnGuildMemberLeave(event *events.GuildMemberLeave) {
+	if h.bus == nil {
+		return
+	}
+
+	_ = h.bus.PublishEvent(context.Background(), bus.MemberLeft{
+		GuildID:  event.GuildID,
+		UserID:   event.User.ID,
+		Username: event.User.Username,
+		Bot:      event.User.Bot,
+		RoleIDs:  event.Member.RoleIDs,
+	})
+}
+
+// OnGuildAuditLogEntryCreate forwards kicks and bans. Discord only sends these to bots with View Audit Log.
+func (h *Handler) OnGuildAuditLogEntryCreate(event *events.GuildAuditLogEntryCreate) {
+	if h.bus == nil {
+		return
+	}
+
+	entry := event.AuditLogEntry
+	var action string
+	switch entry.ActionType {
+	case discord.AuditLogEventMemberKick:
+		action = bus.MemberActionKick
+	case discord.AuditLogEventMemberBanAdd:
+		action = bus.MemberActionBan
+	case discord.AuditLogEventMemberBanRemove:
+		action = bus.MemberActionUnban
+	default:
+		return
+	}
+	if entry.TargetID == nil {
+		return
+	}
+
+	reason := ""
+	if entry.Reason != nil {
+		reason = *entry.Reason
+	}
+
+	_ = h.bus.PublishEvent(context.Background(), bus.MemberModerated{
+		GuildID: event.GuildID,
+		UserID:  *entry.TargetID,
+		ActorID: entry.UserID,
+		Action:  action,
+		Reason:  reason,
+	})
+}
+
+// roleDifference returns the role IDs in roleIDs that are missing from other.
+// The old member is empty when it wasn't cached, so removals are only reported for cached members.
+func roleDifference(roleIDs []snowflake.ID, other []snowflake.ID) []snowflake.ID {
+	var diff []snowflake.ID
+	for _, roleID := range roleIDs {
+		if !memberHasRole(other, roleID) {
+			diff = append(diff, roleID)
+		}
+	}
+	return diff
+}
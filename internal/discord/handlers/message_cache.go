@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/disgoorg/snowflake/v2"
+)
+
+const defaultMessageCacheSize = 5000
+
+type cachedAttachment struct {
+	Filename string
+	URL      string
+}
+
+type cachedMessage struct {
+	ID          snowflake.ID
+	GuildID     snowflake.ID
+	ChannelID   snowflake.ID
+	AuthorID    snowflake.ID
+	AuthorName  string
+	Content     string
+	Attachments []cachedAttachment
+	CreatedAt   time.Time
+}
+
+// messageCache keeps the most recently seen messages, evicting the oldest once full.
+type messageCache struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List
+	items    map[snowflake.ID]*list.Element
+}
+
+func newMessageCache(capacity int) *messageCache {
+	if capacity <= 0 {
+		capacity = defaultMessageCacheSize
+	}
+	return &messageCache{
+		capacity: capacity,
+		order:    list.New(),
+		items:    make(map[snowflake.ID]*list.Element, capacity),
+	}
+}
+
+func (c *messageCache) put(message cachedMessage) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.items[message.ID]; ok {
+		element.Value = message
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.items[message.ID] = c.order.PushFront(message)
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(cachedMessage).ID)
+	}
+}
+
+func (c *messageCache) get(messageID snowflake.ID) (cachedMessage, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.items[messageID]
+	if !ok {
+		return cachedMessage{}, false
+	}
+	return element.Value.(cachedMessage), true
+}
+
+func (c *messageCache) remove(messageID snowflake.ID) (cachedMessage, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.items[messageID]
+	if !ok {
+		return cachedMessage{}, false
+	}
+	c.order.Remove(element)
+	delete(c.items, messageID)
+	return element.Value.(cachedMessage), true
+}
+
+// clear drops every cached message.
+func (c *messageCache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.order.Init()
+	c.items = make(map[snowflake.ID]*list.Element, c.capacity)
+}
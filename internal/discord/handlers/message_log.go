@@ -0,0 +1,265 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"antartica-bot/internal/bus"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/events"
+	"github.com/disgoorg/disgo/gateway"
+	"github.com/disgoorg/snowflake/v2"
+)
+
+const (
+	messageLogCategory = "messages"
+	// maxBulkTranscriptLength keeps bulk delete transcripts inside a single embed description.
+	maxBulkTranscriptLength = 4000
+	maxBulkLineLength       = 200
+	// messageLogPublishTimeout bounds how long a full action queue can hold up the gateway goroutine.
+	// A message log entry that doesn't fit in time is dropped.
+	messageLogPublishTimeout = 250 * time.Millisecond
+)
+
+// publishMessageLog queues a message log entry. It runs on the gateway goroutine, so it waits only
+// briefly for room rather than for the action queue's publish timeout.
+func (h *Handler) publishMessageLog(event bus.LogEvent) {
+	ctx, cancel := context.WithTimeout(context.Background(), messageLogPublishTimeout)
+	defer cancel()
+	_ = h.bus.PublishAction(ctx, event)
+}
+
+// EnableMessageLog starts caching guild messages so edits and deletes can be logged with their content.
+func (h *Handler) EnableMessageLog(cacheSize int) {
+	h.messageCache = newMessageCache(cacheSize)
+}
+
+// SetMessageLogActive pauses or resumes a message log enabled at startup. It returns false when
+// asked to resume a log that was never enabled, since that needs intents and raw events set at connect time.
+// Pausing clears the cache so edits made meanwhile aren't logged against stale content.
+func (h *Handler) SetMessageLogActive(active bool) bool {
+	if h.messageCache == nil {
+		return !active
+	}
+	if !active && !h.messageLogPaused.Swap(true) {
+		h.messageCache.clear()
+	}
+	if active {
+		h.messageLogPaused.Store(false)
+	}
+	return true
+}
+
+func (h *Handler) messageLogActive() bool {
+	return h.messageCache != nil && !h.messageLogPaused.Load()
+}
+
+func (h *Handler) OnGuildMessageCreate(event *events.GuildMessageCreate) {
+	if !h.messageLogActive() || event.Message.Author.Bot || event.Message.WebhookID != nil {
+		return
+	}
+
+	h.messageCache.put(newCachedMessage(event.GuildID, event.Message))
+}
+
+func (h *Handler) OnGuildMessageUpdate(event *events.GuildMessageUpdate) {
+	if !h.messageLogActive() || event.Message.Author.Bot || event.Message.WebhookID != nil {
+		return
+	}
+
+	updated := newCachedMessage(event.GuildID, event.Message)
+	previous, ok := h.messageCache.get(event.MessageID)
+	h.messageCache.put(updated)
+	if !ok || h.bus == nil {
+		return
+	}
+
+	// Embed unfurls also arrive as updates, so only log when the text or attachments changed.
+	if previous.Content == updated.Content && len(previous.Attachments) == len(updated.Attachments) {
+		return
+	}
+
+	fields := []bus.LogField{
+		{Name: "Author", Value: formatMessageAuthor(updated), Inline: true},
+		{Name: "Channel", Value: fmt.Sprintf("<#%s>", updated.ChannelID), Inline: true},
+		{Name: "Before", Value: formatMessageContent(previous.Content)},
+		{Name: "After", Value: formatMessageContent(updated.Content)},
+	}
+	if removed := removedAttachments(previous.Attachments, updated.Attachments); len(removed) > 0 {
+		fields = append(fields, bus.LogField{Name: "Removed attachments", Value: formatAttachments(removed)})
+	}
+
+	h.publishMessageLog(bus.LogEvent{
+		GuildID:     event.GuildID,
+		Category:    messageLogCategory,
+		Level:       bus.LogInfo,
+		Title:       "Message edited",
+		Description: fmt.Sprintf("[Jump to message](%s)", messageJumpURL(updated)),
+		Fields:      fields,
+		Timestamp:   time.Now(),
+	})
+}
+
+// OnRaw picks up bulk deletes before disgo splits them into single delete events,
+// so a purge is logged as one transcript rather than one entry per message.
+func (h *Handler) OnRaw(event *events.Raw) {
+	if !h.messageLogActive() || h.bus == nil || event.EventType != gateway.EventTypeMessageDeleteBulk {
+		return
+	}
+
+	var payload gateway.EventMessageDeleteBulk
+	if err := json.NewDecoder(event.Payload).Decode(&payload); err != nil {
+		h.logger.Debug("bulk delete payload decode failed", slog.Any("err", err))
+		return
+	}
+	if payload.GuildID == nil {
+		return
+	}
+
+	var deleted []cachedMessage
+	for _, messageID := range payload.IDs {
+		if message, ok := h.messageCache.remove(messageID); ok {
+			deleted = append(deleted, message)
+		}
+	}
+
+	fields := []bus.LogField{
+		{Name: "Channel", Value: fmt.Sprintf("<#%s>", payload.ChannelID), Inline: true},
+		{Name: "Deleted", Value: fmt.Sprintf("%d", len(payload.IDs)), Inline: true},
+	}
+	if uncached := len(payload.IDs) - len(deleted); uncached > 0 {
+		fields = append(fields, bus.LogField{Name: "Not cached", Value: fmt.Sprintf("%d", uncached), Inline: true})
+	}
+
+	h.publishMessageLog(bus.LogEvent{
+		GuildID:     *payload.GuildID,
+		Category:    messageLogCategory,
+		Level:       bus.LogWarn,
+		Title:       "Messages bulk deleted",
+		Description: formatBulkTranscript(deleted),
+		Fields:      fields,
+		Timestamp:   time.Now(),
+	})
+}
+
+// logMessageDeleted posts the content of a deleted message, if it was cached.
+// Messages removed by a bulk delete were already taken out of the cache by OnRaw.
+func (h *Handler) logMessageDeleted(event *events.GuildMessageDelete) {
+	if !h.messageLogActive() || h.bus == nil {
+		return
+	}
+
+	message, ok := h.messageCache.remove(event.MessageID)
+	if !ok {
+		return
+	}
+
+	fields := []bus.LogField{
+		{Name: "Author", Value: formatMessageAuthor(message), Inline: true},
+		{Name: "Channel", Value: fmt.Sprintf("<#%s>", message.ChannelID), Inline: true},
+		{Name: "Sent", Value: fmt.Sprintf("<t:%d:R>", message.CreatedAt.Unix()), Inline: true},
+	}
+	if len(message.Attachments) > 0 {
+		fields = append(fields, bus.LogField{Name: "Attachments", Value: formatAttachments(message.Attachments)})
+	}
+
+	h.publishMessageLog(bus.LogEvent{
+		GuildID:     event.GuildID,
+		Category:    messageLogCategory,
+		Level:       bus.LogInfo,
+		Title:       "Message deleted",
+		Description: formatMessageContent(message.Content),
+		Fields:      fields,
+		Timestamp:   time.Now(),
+	})
+}
+
+func newCachedMessage(guildID snowflake.ID, message discord.Message) cachedMessage {
+	attachments := make([]cachedAttachment, 0, len(message.Attachments))
+	for _, attachment := range message.Attachments {
+		attachments = append(attachments, cachedAttachment{
+			Filename: attachment.Filename,
+			URL:      attachment.URL,
+		})
+	}
+
+	return cachedMessage{
+		ID:          message.ID,
+		GuildID:     guildID,
+		ChannelID:   message.ChannelID,
+		AuthorID:    message.Author.ID,
+		AuthorName:  message.Author.Username,
+		Content:     message.Content,
+		Attachments: attachments,
+		CreatedAt:   message.CreatedAt,
+	}
+}
+
+func removedAttachments(before []cachedAttachment, after []cachedAttachment) []cachedAttachment {
+	remaining := make(map[string]struct{}, len(after))
+	for _, attachment := range after {
+		remaining[attachment.URL] = struct{}{}
+	}
+
+	var removed []cachedAttachment
+	for _, attachment := range before {
+		if _, ok := remaining[attachment.URL]; !ok {
+			removed = append(removed, attachment)
+		}
+	}
+	return removed
+}
+
+func formatMessageAuthor(message cachedMessage) string {
+	if message.AuthorName == "" {
+		return fmt.Sprintf("<@%s>", message.AuthorID)
+	}
+	return fmt.Sprintf("<@%s> (%s)", message.AuthorID, message.AuthorName)
+}
+
+func formatMessageContent(content string) string {
+	if strings.TrimSpace(content) == "" {
+		return "*No text content*"
+	}
+	return content
+}
+
+func formatAttachments(attachments []cachedAttachment) string {
+	lines := make([]string, 0, len(attachments))
+	for _, attachment := range attachments {
+		lines = append(lines, fmt.Sprintf("[%s](%s)", attachment.Filename, attachment.URL))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func formatBulkTranscript(messages []cachedMessage) string {
+	if len(messages) == 0 {
+		return "None of the deleted messages were cached."
+	}
+
+	var builder strings.Builder
+	for index, message := range messages {
+		content := strings.ReplaceAll(message.Content, "\n", " ")
+		if len(message.Attachments) > 0 {
+			content = strings.TrimSpace(fmt.Sprintf("%s [%d attachment(s)]", content, len(message.Attachments)))
+		}
+		line := truncateText(fmt.Sprintf("<t:%d:t> <@%s>: %s", message.CreatedAt.Unix(), message.AuthorID, content), maxBulkLineLength)
+
+		if builder.Len()+len(line)+1 > maxBulkTranscriptLength {
+			builder.WriteString(fmt.Sprintf("…and %d more", len(messages)-index))
+			break
+		}
+		builder.WriteString(line)
+		builder.WriteString("\n")
+	}
+	return strings.TrimSpace(builder.String())
+}
+
+func messageJumpURL(message cachedMessage) string {
+	return fmt.Sprintf("https://discord.com/channels/%s/%s/%s", message.GuildID, message.ChannelID, message.ID)
+}
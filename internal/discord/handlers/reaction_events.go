@@ -21,14 +21,14 @@ func (h *Handler) OnGuildMessageReactionRemove(event *events.GuildMessageReactio
 		emojiName = *event.Emoji.Name
 	}
 
-	h.bus.DiscordEvents <- bus.ReactionRemoved{
+	_ = h.bus.PublishEvent(context.Background(), bus.ReactionRemoved{
 		GuildID:   event.GuildID,
 		ChannelID: event.ChannelID,
 		MessageID: event.MessageID,
 		UserID:    event.UserID,
 		EmojiName: emojiName,
 		EmojiID:   event.Emoji.ID,
-	}
+	})
 }
 
 func (h *Handler) OnGuildMessageReactionRemoveEmoji(event *events.GuildMessageReactionRemoveEmoji) {
@@ -41,13 +41,13 @@ func (h *Handler) OnGuildMessageReactionRemoveEmoji(event *events.GuildMessageRe
 		emojiName = *event.Emoji.Name
 	}
 
-	h.bus.DiscordEvents <- bus.ReactionRemovedEmoji{
+	_ = h.bus.PublishEvent(context.Background(), bus.ReactionRemovedEmoji{
 		GuildID:   event.GuildID,
 		ChannelID: event.ChannelID,
 		MessageID: event.MessageID,
 		EmojiName: emojiName,
 		EmojiID:   event.Emoji.ID,
-	}
+	})
 }
 
 func (h *Handler) OnGuildMessageReactionRemoveAll(event *events.GuildMessageReactionRemoveAll) {
@@ -55,11 +55,11 @@ func (h *Handler) OnGuildMessageReactionRemoveAll(event *events.GuildMessageReac
 		return
 	}
 
-	h.bus.DiscordEvents <- bus.ReactionRemovedAll{
+	_ = h.bus.PublishEvent(context.Background(), bus.ReactionRemovedAll{
 		GuildID:   event.GuildID,
 		ChannelID: event.ChannelID,
 		MessageID: event.MessageID,
-	}
+	})
 }
 
 func (h *Handler) OnGuildMessageDelete(event *events.GuildMessageDelete) {
@@ -67,9 +67,11 @@ func (h *Handler) OnGuildMessageDelete(event *events.GuildMessageDelete) {
 		return
 	}
 
-	h.bus.DiscordEvents <- bus.MessageDeleted{
+	h.logMessageDeleted(event)
+
+	_ = h.bus.PublishEvent(context.Background(), bus.MessageDeleted{
 		GuildID:   event.GuildID,
 		ChannelID: event.ChannelID,
 		MessageID: event.MessageID,
-	}
+	})
 }
@@ -13,6 +13,7 @@ func respondEphemeralTone(event *events.ApplicationCommandInteractionCreate, ton
 }
 
 func respondEphemeralEmbed(event *events.ApplicationCommandInteractionCreate, template EmbedTemplate) error {
+	recordAuditTone(event, template.Tone, template.Description)
 	embed := BuildEmbed(template)
 	return event.CreateMessage(discord.MessageCreate{
 		Embeds: []discord.Embed{embed},
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+
+	"antartica-bot/internal/bus"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/events"
+	"github.com/disgoorg/snowflake/v2"
+)
+
+func (h *Handler) OnRoleUpdate(event *events.RoleUpdate) {
+	if h.bus == nil {
+		return
+	}
+
+	if h.isBotRole(event.GuildID, event.RoleID) {
+		// A change to the bot's own role can affect every role below it, so re-check them all in one event.
+		roles := make([]discord.Role, 0, h.client.Caches().RolesLen(event.GuildID))
+		h.client.Caches().RolesForEach(event.GuildID, func(role discord.Role) {
+			roles = append(roles, role)
+		})
+		updates, ok := h.roleManageability(event.GuildID, roles)
+		if ok {
+			_ = h.bus.PublishEvent(context.Background(), bus.RolesUpdated{GuildID: event.GuildID, Roles: updates})
+		}
+		return
+	}
+
+	if event.Role.Position == event.OldRole.Position && event.Role.Managed == event.OldRole.Managed {
+		return
+	}
+	if updates, ok := h.roleManageability(event.GuildID, []discord.Role{event.Role}); ok {
+		_ = h.bus.PublishEvent(context.Background(), updates[0])
+	}
+}
+
+func (h *Handler) OnRoleDelete(event *events.RoleDelete) {
+	if h.bus == nil {
+		return
+	}
+
+	_ = h.bus.PublishEvent(context.Background(), bus.RoleDeleted{
+		GuildID: event.GuildID,
+		RoleID:  event.RoleID,
+	})
+}
+
+// roleManageability works out whether the bot can still manage each role. It returns false when the
+// bot's own roles can't be resolved.
+func (h *Handler) roleManageability(guildID snowflake.ID, roles []discord.Role) ([]bus.RoleUpdated, bool) {
+	state, err := resolveBotRoleState(h.client, guildID)
+	if err != nil {
+		h.logger.Warn("unable to resolve bot role state", slog.Any("err", err), slog.String("guild_id", guildID.String()))
+		return nil, false
+	}
+
+	updates := make([]bus.RoleUpdated, 0, len(roles))
+	for _, role := range roles {
+		manageable, reason := canBotManageRoleWithState(guildID, role, state)
+		updates = append(updates, bus.RoleUpdated{
+			GuildID:    guildID,
+			RoleID:     role.ID,
+			Name:       role.Name,
+			Manageable: manageable,
+			Reason:     reason,
+		})
+	}
+	return updates, len(updates) > 0
+}
+
+func (h *Handler) isBotRole(guildID snowflake.ID, roleID snowflake.ID) bool {
+	selfMember, ok := h.client.Caches().SelfMember(guildID)
+	if !ok {
+		return false
+	}
+	return memberHasRole(selfMember.RoleIDs, roleID)
+}
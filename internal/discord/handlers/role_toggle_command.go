@@ -8,62 +8,51 @@ import (
 	"strconv"
 	"strings"
 
+	"antartica-bot/internal/bus"
 	"antartica-bot/internal/discord/commands"
 
+	"github.com/disgoorg/disgo/bot"
 	"github.com/disgoorg/disgo/cache"
 	"github.com/disgoorg/disgo/discord"
 	"github.com/disgoorg/disgo/events"
 	"github.com/disgoorg/snowflake/v2"
 )
 
+// OnApplicationCommand hands slash commands to the router built from the command registry.
 func (h *Handler) OnApplicationCommand(event *events.ApplicationCommandInteractionCreate) {
-	if event.ApplicationCommandInteraction.Data.Type() != discord.ApplicationCommandTypeSlash {
-		return
-	}
-
-	data := event.SlashCommandInteractionData()
-	switch data.CommandName() {
-	case commands.RoleToggleCommandName:
-		if event.GuildID() == nil {
-			_ = respondEphemeralTone(event, EmbedDecline, "This command can only be used in a server.")
-			return
-		}
-
-		switch data.CommandPath() {
-		case "/role/add":
-			h.handleRoleToggleAdd(event, data)
-		case "/role/remove":
-			h.handleRoleToggleRemove(event, data)
-		case "/role/message-create":
-			h.handleRoleToggleMessageCreate(event, data)
-		case "/role/message-remove":
-			h.handleRoleToggleMessageRemove(event, data)
-		case "/role/message-list":
-			h.handleRoleToggleMessageList(event)
-		default:
-			_ = respondEphemeralTone(event, EmbedWarn, "Unknown subcommand.")
-		}
-	case commands.RoleSelfCommandName:
-		h.handleRoleToggleSelf(event, data)
-	case commands.ReactionCommandName:
-		h.handleReactionCommand(event, data)
-	case commands.BotCommandName:
-		h.handleBotCommand(event, data)
-	}
+	h.router.Handle(event)
 }
 
-func (h *Handler) handleRoleToggleAdd(event *events.ApplicationCommandInteractionCreate, data discord.SlashCommandInteractionData) {
+func (h *Handler) RoleToggleAdd(c *commands.Context) error {
+	event, data := c.Event, c.Data
 	role, ok := data.OptRole("role")
 	if !ok {
 		_ = respondEphemeralTone(event, EmbedWarn, "Role is required.")
-		return
+		return nil
 	}
 
-	descriptionRaw, ok := data.OptString("description")
-	descriptionRaw = strings.TrimSpace(descriptionRaw)
-	var description *string
-	if ok && descriptionRaw != "" {
-		description = &descriptionRaw
+	details := bus.RoleToggleDetails{}
+	if descriptionRaw, ok := data.OptString("description"); ok {
+		if descriptionRaw = strings.TrimSpace(descriptionRaw); descriptionRaw != "" {
+			details.Description = &descriptionRaw
+		}
+	}
+	if category, ok := data.OptString("category"); ok {
+		category = strings.TrimSpace(category)
+		details.Category = &category
+	}
+	if emoji, ok := data.OptString("emoji"); ok {
+		emoji = strings.TrimSpace(emoji)
+		if emoji != "" {
+			if _, _, err := parseEmojiInput(emoji); err != nil {
+				_ = respondEphemeralTone(event, EmbedWarn, "Emoji is invalid.")
+				return nil
+			}
+		}
+		details.Emoji = &emoji
+	}
+	if sortOrder, ok := data.OptInt("order"); ok {
+		details.SortOrder = &sortOrder
 	}
 
 	permissionsRaw, _ := data.OptString("permissions")
@@ -74,50 +63,42 @@ func (h *Handler) handleRoleToggleAdd(event *events.ApplicationCommandInteractio
 	permissionsValue, err := strconv.ParseUint(permissionsRaw, 10, 64)
 	if err != nil {
 		_ = respondEphemeralTone(event, EmbedWarn, "Permissions must be a whole number.")
-		return
+		return nil
 	}
 	permissions := strconv.FormatUint(permissionsValue, 10)
 
 	if ok, reason := h.canManageRole(event, role); !ok {
 		_ = respondEphemeralTone(event, EmbedDecline, reason)
-		return
-	}
-
-	if h.roleToggleStore == nil {
-		_ = respondEphemeralTone(event, EmbedError, "Role toggle store is not configured.")
-		return
+		return nil
 	}
 
 	guildID := *event.GuildID()
-	created, err := h.roleToggleStore.UpsertRoleToggle(context.Background(), guildID, role.ID, permissions, description)
+	created, err := h.roleToggleStore.UpsertRoleToggle(context.Background(), guildID, role.ID, permissions, details)
 	if err != nil {
 		h.logger.Error("failed to save role toggle", slog.Any("err", err))
 		_ = respondEphemeralTone(event, EmbedError, "Failed to save role toggle.")
-		return
+		return nil
 	}
 
 	if created {
 		_ = respondEphemeralTone(event, EmbedSuccess, fmt.Sprintf("Added %s to the role toggles.", role.Mention()))
-		return
+		return nil
 	}
 	_ = respondEphemeralTone(event, EmbedSuccess, fmt.Sprintf("Updated %s in the role toggles.", role.Mention()))
+	return nil
 }
 
-func (h *Handler) handleRoleToggleRemove(event *events.ApplicationCommandInteractionCreate, data discord.SlashCommandInteractionData) {
+func (h *Handler) RoleToggleRemove(c *commands.Context) error {
+	event, data := c.Event, c.Data
 	role, ok := data.OptRole("role")
 	if !ok {
 		_ = respondEphemeralTone(event, EmbedWarn, "Role is required.")
-		return
+		return nil
 	}
 
 	if ok, reason := h.canManageRole(event, role); !ok {
 		_ = respondEphemeralTone(event, EmbedDecline, reason)
-		return
-	}
-
-	if h.roleToggleStore == nil {
-		_ = respondEphemeralTone(event, EmbedError, "Role toggle store is not configured.")
-		return
+		return nil
 	}
 
 	guildID := *event.GuildID()
@@ -125,15 +106,16 @@ func (h *Handler) handleRoleToggleRemove(event *events.ApplicationCommandInterac
 	if err != nil {
 		h.logger.Error("failed to remove role toggle", slog.Any("err", err))
 		_ = respondEphemeralTone(event, EmbedError, "Failed to remove role toggle.")
-		return
+		return nil
 	}
 
 	if deleted == 0 {
 		_ = respondEphemeralTone(event, EmbedInfo, fmt.Sprintf("%s was not in the role toggles.", role.Mention()))
-		return
+		return nil
 	}
 
 	_ = respondEphemeralTone(event, EmbedSuccess, fmt.Sprintf("Removed %s from the role toggles.", role.Mention()))
+	return nil
 }
 
 func (h *Handler) canBotManageRole(event *events.ApplicationCommandInteractionCreate, role discord.Role) (bool, string) {
@@ -146,14 +128,14 @@ func (h *Handler) canBotManageRole(event *events.ApplicationCommandInteractionCr
 		return false, "You can't toggle managed roles."
 	}
 
-	state, err := h.resolveBotRoleState(event, guildID)
+	state, err := resolveBotRoleState(event.Client(), guildID)
 	if err != nil {
 		if h.logger != nil {
 			h.logger.Warn("unable to resolve bot role state", slog.Any("err", err))
 		}
 		return false, "Bot permissions could not be verified yet."
 	}
-	return canBotManageRoleWithState(event, role, state)
+	return canBotManageRoleWithState(guildID, role, state)
 }
 
 func (h *Handler) canManageRole(event *events.ApplicationCommandInteractionCreate, role discord.Role) (bool, string) {
@@ -165,14 +147,6 @@ func (h *Handler) canManageRole(event *events.ApplicationCommandInteractionCreat
 	}
 
 	caches := event.Client().Caches()
-	memberPermissions := member.Permissions
-	if memberPermissions == 0 {
-		memberPermissions = caches.MemberPermissions(member.Member)
-	}
-
-	if !memberPermissions.Has(discord.PermissionManageRoles) {
-		return false, "You need the Manage Roles permission."
-	}
 
 	if guild, ok := caches.Guild(guildID); ok && guild.OwnerID != member.User.ID {
 		userTop := highestRolePosition(caches, guildID, member.RoleIDs)
@@ -201,8 +175,7 @@ type botRoleState struct {
 	topPosition int
 }
 
-func canBotManageRoleWithState(event *events.ApplicationCommandInteractionCreate, role discord.Role, state botRoleState) (bool, string) {
-	guildID := *event.GuildID()
+func canBotManageRoleWithState(guildID snowflake.ID, role discord.Role, state botRoleState) (bool, string) {
 	if role.ID == guildID {
 		return false, "You can't toggle the @everyone role."
 	}
@@ -218,15 +191,15 @@ func canBotManageRoleWithState(event *events.ApplicationCommandInteractionCreate
 	return true, ""
 }
 
-func (h *Handler) resolveBotRoleState(event *events.ApplicationCommandInteractionCreate, guildID snowflake.ID) (botRoleState, error) {
-	caches := event.Client().Caches()
+func resolveBotRoleState(client bot.Client, guildID snowflake.ID) (botRoleState, error) {
+	caches := client.Caches()
 	selfMember, ok := caches.SelfMember(guildID)
 	if !ok {
-		selfID := event.Client().ApplicationID()
+		selfID := client.ApplicationID()
 		if selfID == 0 {
 			return botRoleState{}, errors.New("bot id not cached")
 		}
-		restMember, err := event.Client().Rest().GetMember(guildID, selfID)
+		restMember, err := client.Rest().GetMember(guildID, selfID)
 		if err != nil {
 			return botRoleState{}, err
 		}
@@ -238,7 +211,7 @@ func (h *Handler) resolveBotRoleState(event *events.ApplicationCommandInteractio
 		return state, nil
 	}
 
-	roles, err := event.Client().Rest().GetRoles(guildID)
+	roles, err := client.Rest().GetRoles(guildID)
 	if err != nil {
 		return state, err
 	}
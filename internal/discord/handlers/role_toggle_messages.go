@@ -6,8 +6,9 @@ import (
 	"fmt"
 	"strings"
 
+	"antartica-bot/internal/discord/commands"
+
 	"github.com/disgoorg/disgo/discord"
-	"github.com/disgoorg/disgo/events"
 	"github.com/disgoorg/snowflake/v2"
 )
 
@@ -16,18 +17,17 @@ const staticMessageTypeRoleToggles = "role_toggles"
 type roleToggleMessageConfig struct {
 	Title       string `json:"title,omitempty"`
 	Description string `json:"description,omitempty"`
+	Order       string `json:"order,omitempty"`
+	HideEmoji   bool   `json:"hide_emoji,omitempty"`
+	ShowCounts  bool   `json:"show_counts,omitempty"`
 }
 
-func (h *Handler) handleRoleToggleMessageCreate(event *events.ApplicationCommandInteractionCreate, data discord.SlashCommandInteractionData) {
-	if h.staticMessageStore == nil {
-		_ = respondEphemeralTone(event, EmbedError, "Static message store is not configured.")
-		return
-	}
-
+func (h *Handler) RoleMessageCreate(c *commands.Context) error {
+	event, data := c.Event, c.Data
 	channel, ok := data.OptChannel("channel")
 	if !ok {
 		_ = respondEphemeralTone(event, EmbedWarn, "Channel is required.")
-		return
+		return nil
 	}
 	channelID := channel.ID
 
@@ -36,15 +36,28 @@ func (h *Handler) handleRoleToggleMessageCreate(event *events.ApplicationCommand
 	description, _ := data.OptString("description")
 	description = strings.TrimSpace(description)
 
+	order, _ := data.OptString("order")
+	order = strings.TrimSpace(strings.ToLower(order))
+	showEmoji := true
+	if value, ok := data.OptBool("show_emoji"); ok {
+		showEmoji = value
+	}
+	showCounts, _ := data.OptBool("show_counts")
+
+	messageConfig := roleToggleMessageConfig{
+		Title:       title,
+		Description: description,
+		Order:       order,
+		HideEmoji:   !showEmoji,
+		ShowCounts:  showCounts,
+	}
+
 	config := ""
-	if title != "" || description != "" {
-		configBytes, err := json.Marshal(roleToggleMessageConfig{
-			Title:       title,
-			Description: description,
-		})
+	if messageConfig != (roleToggleMessageConfig{}) {
+		configBytes, err := json.Marshal(messageConfig)
 		if err != nil {
 			_ = respondEphemeralTone(event, EmbedError, "Failed to serialize message config.")
-			return
+			return nil
 		}
 		config = string(configBytes)
 	}
@@ -60,62 +73,56 @@ func (h *Handler) handleRoleToggleMessageCreate(event *events.ApplicationCommand
 	})
 	if err != nil {
 		_ = respondEphemeralTone(event, EmbedError, "Failed to create the role list message.")
-		return
+		return nil
 	}
 
 	guildID := *event.GuildID()
 	if err := h.staticMessageStore.CreateStaticMessage(context.Background(), guildID, channelID, message.ID, staticMessageTypeRoleToggles, config, "instant"); err != nil {
 		_ = event.Client().Rest().DeleteMessage(channelID, message.ID)
 		_ = respondEphemeralTone(event, EmbedError, "Failed to store the role list message.")
-		return
+		return nil
 	}
 
 	_ = respondEphemeralTone(event, EmbedSuccess, fmt.Sprintf("Role list message created in <#%s>.", channelID.String()))
+	return nil
 }
 
-func (h *Handler) handleRoleToggleMessageRemove(event *events.ApplicationCommandInteractionCreate, data discord.SlashCommandInteractionData) {
-	if h.staticMessageStore == nil {
-		_ = respondEphemeralTone(event, EmbedError, "Static message store is not configured.")
-		return
-	}
-
+func (h *Handler) RoleMessageRemove(c *commands.Context) error {
+	event, data := c.Event, c.Data
 	messageRaw, ok := data.OptString("message_id")
 	messageRaw = strings.TrimSpace(messageRaw)
 	if !ok || messageRaw == "" {
 		_ = respondEphemeralTone(event, EmbedWarn, "Message ID is required.")
-		return
+		return nil
 	}
 
 	messageID, err := snowflake.Parse(messageRaw)
 	if err != nil {
 		_ = respondEphemeralTone(event, EmbedWarn, "Message ID must be a number.")
-		return
+		return nil
 	}
 
 	guildID := *event.GuildID()
 	deleted, err := h.staticMessageStore.RemoveStaticMessage(context.Background(), guildID, messageID)
 	if err != nil {
 		_ = respondEphemeralTone(event, EmbedError, "Failed to remove the role list message.")
-		return
+		return nil
 	}
 	if deleted == 0 {
 		_ = respondEphemeralTone(event, EmbedInfo, "That message was not registered.")
-		return
+		return nil
 	}
 	_ = respondEphemeralTone(event, EmbedSuccess, "Role list message removed.")
+	return nil
 }
 
-func (h *Handler) handleRoleToggleMessageList(event *events.ApplicationCommandInteractionCreate) {
-	if h.staticMessageStore == nil {
-		_ = respondEphemeralTone(event, EmbedError, "Static message store is not configured.")
-		return
-	}
-
+func (h *Handler) RoleMessageList(c *commands.Context) error {
+	event := c.Event
 	guildID := *event.GuildID()
 	messages, err := h.staticMessageStore.ListStaticMessages(context.Background(), guildID)
 	if err != nil {
 		_ = respondEphemeralTone(event, EmbedError, "Failed to load role list messages.")
-		return
+		return nil
 	}
 
 	lines := make([]string, 0)
@@ -136,7 +143,7 @@ func (h *Handler) handleRoleToggleMessageList(event *events.ApplicationCommandIn
 
 	if len(lines) == 0 {
 		_ = respondEphemeralTone(event, EmbedInfo, "No role list messages are configured.")
-		return
+		return nil
 	}
 
 	embed := BuildEmbed(EmbedTemplate{
@@ -149,6 +156,7 @@ func (h *Handler) handleRoleToggleMessageList(event *events.ApplicationCommandIn
 		Embeds: []discord.Embed{embed},
 		Flags:  discord.MessageFlagEphemeral,
 	})
+	return nil
 }
 
 func parseRoleToggleMessageConfig(raw string) (roleToggleMessageConfig, error) {
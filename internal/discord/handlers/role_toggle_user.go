@@ -4,32 +4,25 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"sort"
 	"strconv"
 	"strings"
 
 	"antartica-bot/internal/bus"
 	"antartica-bot/internal/discord/commands"
 
+	"github.com/disgoorg/disgo/bot"
 	"github.com/disgoorg/disgo/discord"
 	"github.com/disgoorg/disgo/events"
 	"github.com/disgoorg/snowflake/v2"
 )
 
-func (h *Handler) handleRoleToggleSelf(event *events.ApplicationCommandInteractionCreate, data discord.SlashCommandInteractionData) {
-	if event.GuildID() == nil {
-		_ = respondEphemeralTone(event, EmbedDecline, "This command can only be used in a server.")
-		return
-	}
-
+func (h *Handler) RoleToggleSelf(c *commands.Context) error {
+	event, data := c.Event, c.Data
 	member := event.Member()
 	if member == nil {
 		_ = respondEphemeralTone(event, EmbedError, "Missing member data.")
-		return
-	}
-
-	if h.roleToggleStore == nil {
-		_ = respondEphemeralTone(event, EmbedError, "Role toggle store is not configured.")
-		return
+		return nil
 	}
 
 	guildID := *event.GuildID()
@@ -37,11 +30,11 @@ func (h *Handler) handleRoleToggleSelf(event *events.ApplicationCommandInteracti
 	if err != nil {
 		h.logger.Error("failed to load role toggles", slog.Any("err", err))
 		_ = respondEphemeralTone(event, EmbedError, "Failed to load role toggles.")
-		return
+		return nil
 	}
 	if len(toggles) == 0 {
 		_ = respondEphemeralTone(event, EmbedInfo, "No self-assignable roles are configured.")
-		return
+		return nil
 	}
 
 	caches := event.Client().Caches()
@@ -50,21 +43,28 @@ func (h *Handler) handleRoleToggleSelf(event *events.ApplicationCommandInteracti
 		memberPermissions = caches.MemberPermissions(member.Member)
 	}
 
-	botState, err := h.resolveBotRoleState(event, guildID)
+	botState, err := resolveBotRoleState(event.Client(), guildID)
 	if err != nil {
 		if h.logger != nil {
 			h.logger.Warn("unable to resolve bot role state", slog.Any("err", err))
 		}
 		_ = respondEphemeralTone(event, EmbedWarn, "Bot permissions could not be verified yet.")
-		return
+		return nil
 	}
 
-	if role, ok := data.OptRole("role"); ok {
+	if rawRole, ok := data.OptString("role"); ok && strings.TrimSpace(rawRole) != "" {
+		roleMap := h.resolveToggleRoles(event.Client(), guildID, toggles)
+		role, ok := matchToggleRole(rawRole, toggles, roleMap)
+		if !ok {
+			_ = respondEphemeralTone(event, EmbedDecline, "That role is not self-assignable.")
+			return nil
+		}
 		h.handleRoleToggleSelfRole(event, member, memberPermissions, toggles, role, botState)
-		return
+		return nil
 	}
 
 	h.handleRoleToggleSelfList(event, member, memberPermissions, toggles, botState)
+	return nil
 }
 
 func (h *Handler) handleRoleToggleSelfRole(event *events.ApplicationCommandInteractionCreate, member *discord.ResolvedMember, memberPermissions discord.Permissions, toggles []bus.RoleToggle, role discord.Role, botState botRoleState) {
@@ -88,7 +88,7 @@ func (h *Handler) handleRoleToggleSelfRole(event *events.ApplicationCommandInter
 		return
 	}
 
-	if ok, reason := canBotManageRoleWithState(event, role, botState); !ok {
+	if ok, reason := canBotManageRoleWithState(*event.GuildID(), role, botState); !ok {
 		_ = respondEphemeralTone(event, EmbedDecline, reason)
 		return
 	}
@@ -119,28 +119,7 @@ func (h *Handler) handleRoleToggleSelfRole(event *events.ApplicationCommandInter
 
 func (h *Handler) handleRoleToggleSelfList(event *events.ApplicationCommandInteractionCreate, member *discord.ResolvedMember, memberPermissions discord.Permissions, toggles []bus.RoleToggle, botState botRoleState) {
 	guildID := *event.GuildID()
-	caches := event.Client().Caches()
-	roleMap := make(map[snowflake.ID]discord.Role, len(toggles))
-	missingRoles := false
-	for _, toggle := range toggles {
-		if role, ok := caches.Role(guildID, toggle.RoleID); ok {
-			roleMap[toggle.RoleID] = role
-		} else {
-			missingRoles = true
-		}
-	}
-	if missingRoles {
-		roles, err := event.Client().Rest().GetRoles(guildID)
-		if err != nil {
-			if h.logger != nil {
-				h.logger.Warn("failed to fetch guild roles", slog.Any("err", err))
-			}
-		} else {
-			for _, role := range roles {
-				roleMap[role.ID] = role
-			}
-		}
-	}
+	roleMap := h.resolveToggleRoles(event.Client(), guildID, toggles)
 
 	haveLines := make([]string, 0)
 	availableLines := make([]string, 0)
@@ -160,7 +139,7 @@ func (h *Handler) handleRoleToggleSelfList(event *events.ApplicationCommandInter
 		}
 
 		if ok {
-			if ok, _ := canBotManageRoleWithState(event, role, botState); !ok {
+			if ok, _ := canBotManageRoleWithState(guildID, role, botState); !ok {
 				continue
 			}
 		}
@@ -211,6 +190,56 @@ func (h *Handler) handleRoleToggleSelfList(event *events.ApplicationCommandInter
 	})
 }
 
+// resolveToggleRoles looks up the Discord roles for the given toggles, falling back to REST when the cache is incomplete.
+func (h *Handler) resolveToggleRoles(client bot.Client, guildID snowflake.ID, toggles []bus.RoleToggle) map[snowflake.ID]discord.Role {
+	caches := client.Caches()
+	roleMap := make(map[snowflake.ID]discord.Role, len(toggles))
+	missingRoles := false
+	for _, toggle := range toggles {
+		if role, ok := caches.Role(guildID, toggle.RoleID); ok {
+			roleMap[toggle.RoleID] = role
+		} else {
+			missingRoles = true
+		}
+	}
+	if missingRoles {
+		roles, err := client.Rest().GetRoles(guildID)
+		if err != nil {
+			if h.logger != nil {
+				h.logger.Warn("failed to fetch guild roles", slog.Any("err", err))
+			}
+		} else {
+			for _, role := range roles {
+				roleMap[role.ID] = role
+			}
+		}
+	}
+	return roleMap
+}
+
+// matchToggleRole resolves the raw role option to a configured toggle role.
+// Autocomplete sends the role ID, but users can also submit a mention or a role name.
+func matchToggleRole(raw string, toggles []bus.RoleToggle, roleMap map[snowflake.ID]discord.Role) (discord.Role, bool) {
+	raw = strings.TrimSpace(raw)
+	trimmed := strings.TrimSuffix(strings.TrimPrefix(raw, "<@&"), ">")
+	if roleID, err := snowflake.Parse(trimmed); err == nil {
+		if _, ok := findRoleToggle(toggles, roleID); !ok {
+			return discord.Role{}, false
+		}
+		role, ok := roleMap[roleID]
+		return role, ok
+	}
+
+	name := strings.TrimPrefix(raw, "@")
+	for _, toggle := range toggles {
+		role, ok := roleMap[toggle.RoleID]
+		if ok && strings.EqualFold(role.Name, name) {
+			return role, true
+		}
+	}
+	return discord.Role{}, false
+}
+
 func findRoleToggle(toggles []bus.RoleToggle, roleID snowflake.ID) (bus.RoleToggle, bool) {
 	for _, toggle := range toggles {
 		if toggle.RoleID == roleID {
@@ -271,3 +300,97 @@ func removeRole(roleIDs []snowflake.ID, roleID snowflake.ID) ([]snowflake.ID, bo
 	}
 	return updated, removed
 }
+
+func (h *Handler) handleRoleToggleSelfAutocomplete(event *events.AutocompleteInteractionCreate) {
+	if event.GuildID() == nil || h.roleToggleStore == nil {
+		_ = respondAutocomplete(event, nil)
+		return
+	}
+
+	member := event.Member()
+	if member == nil {
+		_ = respondAutocomplete(event, nil)
+		return
+	}
+
+	guildID := *event.GuildID()
+	toggles, err := h.roleToggleStore.ListRoleToggles(context.Background(), guildID)
+	if err != nil {
+		h.logger.Warn("failed to load role toggles for autocomplete", slog.Any("err", err))
+		_ = respondAutocomplete(event, nil)
+		return
+	}
+	if len(toggles) == 0 {
+		_ = respondAutocomplete(event, nil)
+		return
+	}
+
+	memberPermissions := member.Permissions
+	if memberPermissions == 0 {
+		memberPermissions = event.Client().Caches().MemberPermissions(member.Member)
+	}
+
+	botState, err := resolveBotRoleState(event.Client(), guildID)
+	if err != nil {
+		h.logger.Debug("unable to resolve bot role state for autocomplete", slog.Any("err", err))
+		_ = respondAutocomplete(event, nil)
+		return
+	}
+
+	roleMap := h.resolveToggleRoles(event.Client(), guildID, toggles)
+	query := strings.ToLower(strings.TrimSpace(event.Data.String("role")))
+
+	type roleCandidate struct {
+		role        discord.Role
+		description string
+	}
+
+	candidates := make([]roleCandidate, 0, len(toggles))
+	for _, toggle := range toggles {
+		role, ok := roleMap[toggle.RoleID]
+		if !ok {
+			continue
+		}
+
+		requiredPermissions, err := parseRoleTogglePermissions(toggle.Permissions)
+		if err != nil {
+			continue
+		}
+		if requiredPermissions != 0 && !memberPermissions.Has(requiredPermissions) {
+			continue
+		}
+		if ok, _ := canBotManageRoleWithState(guildID, role, botState); !ok {
+			continue
+		}
+
+		description := strings.TrimSpace(toggle.Description)
+		if query != "" && !strings.Contains(strings.ToLower(role.Name), query) && !strings.Contains(strings.ToLower(description), query) {
+			continue
+		}
+		candidates = append(candidates, roleCandidate{role: role, description: description})
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].role.Position == candidates[j].role.Position {
+			return candidates[i].role.Name < candidates[j].role.Name
+		}
+		return candidates[i].role.Position > candidates[j].role.Position
+	})
+
+	choices := make([]discord.AutocompleteChoice, 0, min(len(candidates), maxAutocompleteChoices))
+	for _, candidate := range candidates {
+		if len(choices) == maxAutocompleteChoices {
+			break
+		}
+		name := formatRoleLineMention(candidate.role.Name, candidate.description)
+		if memberHasRole(member.RoleIDs, candidate.role.ID) {
+			name = "✓ " + name
+		}
+		choices = append(choices, discord.AutocompleteChoiceString{
+			Name:  truncateChoiceName(name),
+			Value: candidate.role.ID.String(),
+		})
+	}
+
+	_ = respondAutocomplete(event, choices)
+}
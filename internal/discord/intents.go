@@ -0,0 +1,83 @@
+package discord
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/disgoorg/disgo/gateway"
+)
+
+var intentNames = map[string]gateway.Intents{
+	"guilds":                        gateway.IntentGuilds,
+	"guild_members":                 gateway.IntentGuildMembers,
+	"guild_moderation":              gateway.IntentGuildModeration,
+	"guild_expressions":             gateway.IntentGuildExpressions,
+	"guild_integrations":            gateway.IntentGuildIntegrations,
+	"guild_webhooks":                gateway.IntentGuildWebhooks,
+	"guild_invites":                 gateway.IntentGuildInvites,
+	"guild_voice_states":            gateway.IntentGuildVoiceStates,
+	"guild_presences":               gateway.IntentGuildPresences,
+	"guild_messages":                gateway.IntentGuildMessages,
+	"guild_message_reactions":       gateway.IntentGuildMessageReactions,
+	"guild_message_typing":          gateway.IntentGuildMessageTyping,
+	"direct_messages":               gateway.IntentDirectMessages,
+	"direct_message_reactions":      gateway.IntentDirectMessageReactions,
+	"direct_message_typing":         gateway.IntentDirectMessageTyping,
+	"message_content":               gateway.IntentMessageContent,
+	"guild_scheduled_events":        gateway.IntentGuildScheduledEvents,
+	"auto_moderation_configuration": gateway.IntentAutoModerationConfiguration,
+	"auto_moderation_execution":     gateway.IntentAutoModerationExecution,
+	"guild_message_polls":           gateway.IntentGuildMessagePolls,
+	"direct_message_polls":          gateway.IntentDirectMessagePolls,
+}
+
+// baseIntents are always requested: role and channel caches, reaction tracking and moderation audit log entries.
+const baseIntents = gateway.IntentGuilds | gateway.IntentGuildMessageReactions | gateway.IntentGuildModeration
+
+// ParseIntents turns intent names such as guild_messages into gateway intents.
+// An empty list returns 0, which New replaces with the non-privileged intents.
+func ParseIntents(names []string) (gateway.Intents, error) {
+	var intents gateway.Intents
+	var unknown []string
+	for _, name := range names {
+		key := strings.ToLower(strings.TrimSpace(name))
+		if key == "" {
+			continue
+		}
+		intent, ok := intentNames[key]
+		if !ok {
+			unknown = append(unknown, name)
+			continue
+		}
+		intents = intents.Add(intent)
+	}
+	if len(unknown) > 0 {
+		known := make([]string, 0, len(intentNames))
+		for name := range intentNames {
+			known = append(known, name)
+		}
+		sort.Strings(known)
+		return 0, fmt.Errorf("unknown gateway intents %s (known: %s)", strings.Join(unknown, ", "), strings.Join(known, ", "))
+	}
+	return intents, nil
+}
+
+// resolveIntents adds what the enabled features need to the configured intents.
+func resolveIntents(cfg Config) gateway.Intents {
+	intents := cfg.Intents
+	if intents == 0 {
+		intents = DefaultConfig().Intents
+	}
+	intents = intents.Add(baseIntents)
+	if cfg.MembersIntent {
+		intents = intents.Add(gateway.IntentGuildMembers)
+	}
+	if cfg.MessageContentIntent {
+		intents = intents.Add(gateway.IntentMessageContent)
+	}
+	if cfg.MessageLog {
+		intents = intents.Add(gateway.IntentGuildMessages)
+	}
+	return intents
+}
@@ -0,0 +1,62 @@
+package discord
+
+import (
+	"context"
+	"strings"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/gateway"
+)
+
+// Presence is what the bot shows on its profile. Unknown statuses fall back to online and unknown activity types to playing.
+type Presence struct {
+	Status       string
+	ActivityType string
+	Activity     string
+}
+
+// presenceOpt replaces the whole presence, so an empty activity clears the previous one.
+// disgo keeps the presence passed at connect time and changes it in place, so resumes and reconnects send the update too.
+func presenceOpt(presence Presence) gateway.PresenceOpt {
+	return func(update *gateway.MessageDataPresenceUpdate) {
+		update.Status = onlineStatus(presence.Status)
+		update.Activities = nil
+		update.AFK = false
+		update.Since = nil
+
+		text := strings.TrimSpace(presence.Activity)
+		if text == "" {
+			return
+		}
+		activity := discord.Activity{Name: text, Type: discord.ActivityTypeGame}
+		switch strings.ToLower(strings.TrimSpace(presence.ActivityType)) {
+		case "listening":
+			activity.Type = discord.ActivityTypeListening
+		case "watching":
+			activity.Type = discord.ActivityTypeWatching
+		case "competing":
+			activity.Type = discord.ActivityTypeCompeting
+		case "custom":
+			activity = discord.Activity{Name: "Custom Status", Type: discord.ActivityTypeCustom, State: &text}
+		}
+		update.Activities = []discord.Activity{activity}
+	}
+}
+
+func onlineStatus(status string) discord.OnlineStatus {
+	switch strings.ToLower(strings.TrimSpace(status)) {
+	case "idle":
+		return discord.OnlineStatusIdle
+	case "dnd":
+		return discord.OnlineStatusDND
+	case "invisible":
+		return discord.OnlineStatusInvisible
+	default:
+		return discord.OnlineStatusOnline
+	}
+}
+
+// SetPresence updates the bot's presence on the open gateway.
+func (b *Bot) SetPresence(ctx context.Context, presence Presence) error {
+	return b.client.SetPresence(ctx, presenceOpt(presence))
+}
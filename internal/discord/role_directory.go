@@ -0,0 +1,59 @@
+package discord
+
+import (
+	"context"
+
+	"antartica-bot/internal/bus"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/gateway"
+	"github.com/disgoorg/snowflake/v2"
+)
+
+// GuildRoles returns the guild's roles from the cache, falling back to REST.
+// Member counts are only filled in when the members intent is enabled, since the member cache is incomplete otherwise.
+func (b *Bot) GuildRoles(_ context.Context, guildID snowflake.ID) (map[snowflake.ID]bus.GuildRole, error) {
+	caches := b.client.Caches()
+	roles := make(map[snowflake.ID]bus.GuildRole, caches.RolesLen(guildID))
+	caches.RolesForEach(guildID, func(role discord.Role) {
+		roles[role.ID] = bus.GuildRole{
+			ID:       role.ID,
+			Name:     role.Name,
+			Position: role.Position,
+		}
+	})
+
+	if len(roles) == 0 {
+		restRoles, err := b.client.Rest().GetRoles(guildID)
+		if err != nil {
+			return nil, err
+		}
+		for _, role := range restRoles {
+			roles[role.ID] = bus.GuildRole{
+				ID:       role.ID,
+				Name:     role.Name,
+				Position: role.Position,
+			}
+		}
+	}
+
+	if !b.intents.Has(gateway.IntentGuildMembers) {
+		return roles, nil
+	}
+
+	counts := make(map[snowflake.ID]int, len(roles))
+	caches.MembersForEach(guildID, func(member discord.Member) {
+		for _, roleID := range member.RoleIDs {
+			counts[roleID]++
+		}
+	})
+	for roleID, role := range roles {
+		role.MemberCount = counts[roleID]
+		role.MemberCountKnown = true
+		roles[roleID] = role
+	}
+
+	return roles, nil
+}
+
+var _ bus.RoleDirectory = (*Bot)(nil)
@@ -0,0 +1,211 @@
+package health
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"antartica-bot/internal/bus"
+
+	"github.com/disgoorg/disgo/gateway"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/router"
+)
+
+const (
+	// StaleGatewayAfter is how long the gateway may stay silent before the bot counts as unhealthy.
+	// Heartbeat acks arrive roughly every 40 seconds while connected.
+	StaleGatewayAfter = 2 * time.Minute
+
+	// Readiness fails while more than this many items are waiting on the bus.
+	maxEventBacklog  = 1000
+	maxActionBacklog = 500
+
+	databaseTimeout = 2 * time.Second
+)
+
+// Gateway is the view of the Discord connection the checks need. *discord.Bot implements it.
+type Gateway interface {
+	GatewayStatus() gateway.Status
+	GatewayLatency() time.Duration
+	LastGatewayEvent() time.Time
+	GatewayResumes() uint64
+}
+
+type Checker struct {
+	app       core.App
+	bus       *bus.Bus
+	gateway   Gateway
+	startedAt time.Time
+
+	mu                sync.Mutex
+	commandsDone      bool
+	commandsErr       error
+	commandsCheckedAt time.Time
+}
+
+func NewChecker(app core.App, eventBus *bus.Bus, gw Gateway) *Checker {
+	return &Checker{
+		app:       app,
+		bus:       eventBus,
+		gateway:   gw,
+		startedAt: time.Now(),
+	}
+}
+
+// SetCommandRegistration records the outcome of registering slash commands.
+func (c *Checker) SetCommandRegistration(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.commandsDone = true
+	c.commandsErr = err
+	c.commandsCheckedAt = time.Now()
+}
+
+// Bind registers GET /api/bot/health (liveness) and GET /api/bot/ready (readiness).
+// Both answer 200 when passing and 503 otherwise, with the full report as JSON.
+func (c *Checker) Bind(r *router.Router[*core.RequestEvent]) {
+	r.GET("/api/bot/health", func(e *core.RequestEvent) error {
+		report := c.Report(e.Request.Context())
+		return e.JSON(statusCode(report.Healthy), report)
+	})
+	r.GET("/api/bot/ready", func(e *core.RequestEvent) error {
+		report := c.Report(e.Request.Context())
+		return e.JSON(statusCode(report.Ready), report)
+	})
+}
+
+type Report struct {
+	// Healthy is false when the gateway has gone silent, meaning the process should be restarted.
+	Healthy bool `json:"healthy"`
+	// Ready is false while the bot can't serve users: not connected, commands missing, database down or bus backed up.
+	Ready    bool           `json:"ready"`
+	Reasons  []string       `json:"reasons,omitempty"`
+	Gateway  GatewayReport  `json:"gateway"`
+	Commands CommandsReport `json:"commands"`
+	Bus      BusReport      `json:"bus"`
+	Database DatabaseReport `json:"database"`
+}
+
+type GatewayReport struct {
+	Status    string `json:"status"`
+	Connected bool   `json:"connected"`
+	Resuming  bool   `json:"resuming"`
+	Resumes   uint64 `json:"resumes"`
+	LatencyMS int64  `json:"latency_ms"`
+	// SecondsSinceEvent counts from startup until the first event arrives.
+	SecondsSinceEvent float64    `json:"seconds_since_event"`
+	LastEventAt       *time.Time `json:"last_event_at,omitempty"`
+}
+
+type CommandsReport struct {
+	Registered bool       `json:"registered"`
+	Error      string     `json:"error,omitempty"`
+	CheckedAt  *time.Time `json:"checked_at,omitempty"`
+}
+
+type BusReport struct {
+	EventBacklog  int `json:"event_backlog"`
+	ActionBacklog int `json:"action_backlog"`
+}
+
+type DatabaseReport struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+}
+
+func (c *Checker) Report(ctx context.Context) Report {
+	report := Report{Healthy: true, Ready: true}
+	now := time.Now()
+
+	status := c.gateway.GatewayStatus()
+	report.Gateway = GatewayReport{
+		Status:    status.String(),
+		Connected: status.IsConnected(),
+		Resuming:  status == gateway.StatusResuming,
+		Resumes:   c.gateway.GatewayResumes(),
+		LatencyMS: c.gateway.GatewayLatency().Milliseconds(),
+	}
+	lastEvent := c.startedAt
+	if last := c.gateway.LastGatewayEvent(); !last.IsZero() {
+		lastEvent = last
+		report.Gateway.LastEventAt = &last
+	}
+	silence := now.Sub(lastEvent)
+	report.Gateway.SecondsSinceEvent = silence.Seconds()
+
+	if silence > StaleGatewayAfter {
+		report.Healthy = false
+		report.fail("no gateway events for " + silence.Round(time.Second).String())
+	}
+	if status != gateway.StatusReady {
+		report.fail("gateway is " + status.String())
+	}
+
+	c.mu.Lock()
+	report.Commands.Registered = c.commandsDone && c.commandsErr == nil
+	if c.commandsErr != nil {
+		report.Commands.Error = c.commandsErr.Error()
+	}
+	if c.commandsDone {
+		checkedAt := c.commandsCheckedAt
+		report.Commands.CheckedAt = &checkedAt
+	}
+	c.mu.Unlock()
+	if !report.Commands.Registered {
+		report.fail("slash commands are not registered")
+	}
+
+	if c.bus != nil {
+		stats := c.bus.Stats()
+		report.Bus = BusReport{
+			EventBacklog:  stats.Events.Depth,
+			ActionBacklog: stats.Actions.Depth,
+		}
+		if stats.Events.Depth > maxEventBacklog {
+			report.fail("event backlog is too large")
+		}
+		if stats.Actions.Depth > maxActionBacklog {
+			report.fail("action backlog is too large")
+		}
+	}
+
+	report.Database = c.checkDatabase(ctx)
+	if !report.Database.OK {
+		report.fail("database is unavailable")
+	}
+
+	return report
+}
+
+func (c *Checker) checkDatabase(ctx context.Context) DatabaseReport {
+	if c.app == nil {
+		return DatabaseReport{Error: "not configured"}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, databaseTimeout)
+	defer cancel()
+
+	var one int
+	if err := c.app.DB().NewQuery("SELECT 1").WithContext(ctx).Row(&one); err != nil {
+		// The endpoints are public, so the driver's message only goes to the log.
+		c.app.Logger().Warn("health database check failed", slog.Any("err", err))
+		return DatabaseReport{Error: "query failed"}
+	}
+	return DatabaseReport{OK: true}
+}
+
+// fail marks the report not ready and records why.
+func (r *Report) fail(reason string) {
+	r.Ready = false
+	r.Reasons = append(r.Reasons, reason)
+}
+
+func statusCode(ok bool) int {
+	if ok {
+		return http.StatusOK
+	}
+	return http.StatusServiceUnavailable
+}
@@ -0,0 +1,101 @@
+package metrics
+
+import (
+	"antartica-bot/internal/bus"
+
+	"github.com/disgoorg/disgo/bot"
+	"github.com/disgoorg/disgo/gateway"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// RegisterBus exposes the bus counters, read from Bus.Stats on every scrape.
+func RegisterBus(eventBus *bus.Bus) error {
+	return prometheus.Register(&busCollector{bus: eventBus})
+}
+
+// RegisterGateway exposes the gateway latency and connection state.
+func RegisterGateway(client bot.Client) error {
+	return prometheus.Register(&gatewayCollector{client: client})
+}
+
+var (
+	queueDepthDesc     = prometheus.NewDesc(namespace+"_bus_queue_depth", "Items waiting in a bus queue, in memory and on disk.", []string{"queue"}, nil)
+	queuePublishedDesc = prometheus.NewDesc(namespace+"_bus_queue_published_total", "Items accepted by a bus queue.", []string{"queue"}, nil)
+	queueDroppedDesc   = prometheus.NewDesc(namespace+"_bus_queue_dropped_total", "Items a bus queue dropped because it was full.", []string{"queue"}, nil)
+	queueSpilledDesc   = prometheus.NewDesc(namespace+"_bus_queue_spilled_total", "Items a bus queue wrote to disk.", []string{"queue"}, nil)
+	eventsHandledDesc  = prometheus.NewDesc(namespace+"_bus_events_handled_total", "Gateway events handled by consumer modules.", []string{"subscriber", "type"}, nil)
+	eventsUnroutedDesc = prometheus.NewDesc(namespace+"_bus_events_unrouted_total", "Gateway events no module subscribed to.", nil, nil)
+	eventsRejectedDesc = prometheus.NewDesc(namespace+"_bus_events_rejected_total", "Gateway events rejected during shutdown.", nil, nil)
+
+	gatewayLatencyDesc   = prometheus.NewDesc(namespace+"_gateway_latency_seconds", "Latency of the last gateway heartbeat.", nil, nil)
+	gatewayConnectedDesc = prometheus.NewDesc(namespace+"_gateway_connected", "1 while the gateway is connected.", nil, nil)
+	gatewayStatusDesc    = prometheus.NewDesc(namespace+"_gateway_status", "1 for the gateway's current connection state.", []string{"status"}, nil)
+)
+
+type busCollector struct {
+	bus *bus.Bus
+}
+
+func (c *busCollector) Describe(descs chan<- *prometheus.Desc) {
+	for _, desc := range []*prometheus.Desc{
+		queueDepthDesc,
+		queuePublishedDesc,
+		queueDroppedDesc,
+		queueSpilledDesc,
+		eventsHandledDesc,
+		eventsUnroutedDesc,
+		eventsRejectedDesc,
+	} {
+		descs <- desc
+	}
+}
+
+func (c *busCollector) Collect(metrics chan<- prometheus.Metric) {
+	stats := c.bus.Stats()
+
+	collectQueue(metrics, "actions", stats.Actions)
+	for name, queueStats := range stats.Subscribers {
+		collectQueue(metrics, "events/"+name, queueStats)
+	}
+	for subscriber, handled := range stats.Handled {
+		for eventType, count := range handled {
+			metrics <- prometheus.MustNewConstMetric(eventsHandledDesc, prometheus.CounterValue, float64(count), subscriber, eventType)
+		}
+	}
+	metrics <- prometheus.MustNewConstMetric(eventsUnroutedDesc, prometheus.CounterValue, float64(stats.Unrouted))
+	metrics <- prometheus.MustNewConstMetric(eventsRejectedDesc, prometheus.CounterValue, float64(stats.Rejected))
+}
+
+func collectQueue(metrics chan<- prometheus.Metric, queue string, stats bus.QueueStats) {
+	metrics <- prometheus.MustNewConstMetric(queueDepthDesc, prometheus.GaugeValue, float64(stats.Depth), queue)
+	metrics <- prometheus.MustNewConstMetric(queuePublishedDesc, prometheus.CounterValue, float64(stats.Published), queue)
+	metrics <- prometheus.MustNewConstMetric(queueDroppedDesc, prometheus.CounterValue, float64(stats.Dropped), queue)
+	metrics <- prometheus.MustNewConstMetric(queueSpilledDesc, prometheus.CounterValue, float64(stats.Spilled), queue)
+}
+
+type gatewayCollector struct {
+	client bot.Client
+}
+
+func (c *gatewayCollector) Describe(descs chan<- *prometheus.Desc) {
+	descs <- gatewayLatencyDesc
+	descs <- gatewayConnectedDesc
+	descs <- gatewayStatusDesc
+}
+
+func (c *gatewayCollector) Collect(metrics chan<- prometheus.Metric) {
+	status := gateway.StatusUnconnected
+	latency := 0.0
+	if gw := c.client.Gateway(); gw != nil {
+		status = gw.Status()
+		latency = gw.Latency().Seconds()
+	}
+
+	connected := 0.0
+	if status.IsConnected() {
+		connected = 1
+	}
+	metrics <- prometheus.MustNewConstMetric(gatewayLatencyDesc, prometheus.GaugeValue, latency)
+	metrics <- prometheus.MustNewConstMetric(gatewayConnectedDesc, prometheus.GaugeValue, connected)
+	metrics <- prometheus.MustNewConstMetric(gatewayStatusDesc, prometheus.GaugeValue, 1, status.String())
+}
@@ -0,0 +1,88 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "antartica"
+
+// Action outcomes recorded by ActionFinished.
+const (
+	ActionSuccess    = "success"
+	ActionRetried    = "retried"
+	ActionFailed     = "failed"
+	ActionRejected   = "rejected"
+	ActionAbandoned  = "abandoned"
+	ActionSuperseded = "superseded"
+)
+
+var (
+	actions = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "actions_total",
+		Help:      "Discord actions by type and outcome. Retries are counted per attempt.",
+	}, []string{"type", "outcome"})
+
+	restErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rest_errors_total",
+		Help:      "Discord REST requests that failed, by method and status code (\"error\" for network failures).",
+	}, []string{"method", "status"})
+
+	commands = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "commands_total",
+		Help:      "Slash command invocations by command path.",
+	}, []string{"command"})
+
+	commandDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "command_duration_seconds",
+		Help:      "Time spent handling slash commands by command path.",
+		Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2, 3, 5, 10},
+	}, []string{"command"})
+)
+
+// Handler serves every registered metric in the Prometheus text format.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+func ActionFinished(actionType string, outcome string) {
+	actions.WithLabelValues(actionType, outcome).Inc()
+}
+
+func CommandHandled(path string, duration time.Duration) {
+	commands.WithLabelValues(path).Inc()
+	commandDuration.WithLabelValues(path).Observe(duration.Seconds())
+}
+
+// Transport counts failed REST requests made through base. A nil base uses http.DefaultTransport.
+func Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return roundTripperFunc(func(request *http.Request) (*http.Response, error) {
+		response, err := base.RoundTrip(request)
+		if err != nil {
+			restErrors.WithLabelValues(request.Method, "error").Inc()
+			return response, err
+		}
+		if response.StatusCode >= http.StatusBadRequest {
+			restErrors.WithLabelValues(request.Method, strconv.Itoa(response.StatusCode)).Inc()
+		}
+		return response, nil
+	})
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(request *http.Request) (*http.Response, error) {
+	return f(request)
+}
@@ -7,80 +7,57 @@ import (
 
 	"antartica-bot/internal/bus"
 
-	"github.com/pocketbase/pocketbase"
+	"github.com/pocketbase/pocketbase/core"
 )
 
-type DiscordConsumer struct {
-	app    *pocketbase.PocketBase
-	bus    *bus.Bus
-	logger *slog.Logger
+// Module subscribes a feature to the gateway events it handles. Each module gets its own queue,
+// so a slow module only backs up its own events.
+type Module func(ctx context.Context, app core.App, eventBus *bus.Bus, logger *slog.Logger) error
 
-	reactions *ReactionProcessor
+var modules []Module
+
+// Register adds a consumer module. Use one file per feature and register in init.
+func Register(module Module) {
+	if module == nil {
+		return
+	}
+	modules = append(modules, module)
+}
+
+func init() {
+	Register(startInteractionModule)
 }
 
-func StartDiscordConsumer(ctx context.Context, app *pocketbase.PocketBase, eventBus *bus.Bus, logger *slog.Logger) {
+// StartDiscordConsumer starts every registered module. Call it before opening the gateway so no events go unrouted.
+func StartDiscordConsumer(ctx context.Context, app core.App, eventBus *bus.Bus, logger *slog.Logger) error {
 	if eventBus == nil {
-		return
+		return nil
 	}
 	if logger == nil {
 		logger = app.Logger()
 	}
 
-	consumer := &DiscordConsumer{
-		app:       app,
-		bus:       eventBus,
-		logger:    logger,
-		reactions: NewReactionProcessor(app, logger),
+	for _, module := range modules {
+		if err := module(ctx, app, eventBus, logger); err != nil {
+			return fmt.Errorf("start consumer module: %w", err)
+		}
 	}
-
-	go consumer.run(ctx)
+	return nil
 }
 
-func (c *DiscordConsumer) run(ctx context.Context) {
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case event, ok := <-c.bus.DiscordEvents:
-			if !ok {
-				return
-			}
-			c.handle(event)
-		}
+func startInteractionModule(ctx context.Context, _ core.App, eventBus *bus.Bus, logger *slog.Logger) error {
+	subscriber, err := eventBus.NewSubscriber("interactions", bus.SubscribeOptions{})
+	if err != nil {
+		return err
 	}
-}
 
-func (c *DiscordConsumer) handle(event bus.DiscordEvent) {
-	switch payload := event.(type) {
-	case bus.ReactionAdded:
-		if c.reactions != nil {
-			c.reactions.HandleReactionAdd(context.Background(), payload)
-		}
-	case bus.ReactionRemoved:
-		if c.reactions != nil {
-			c.reactions.HandleReactionRemove(context.Background(), payload)
-		}
-	case bus.ReactionRemovedEmoji:
-		if c.reactions != nil {
-			c.reactions.HandleReactionRemoveEmoji(context.Background(), payload)
-		}
-	case bus.ReactionRemovedAll:
-		if c.reactions != nil {
-			c.reactions.HandleReactionRemoveAll(context.Background(), payload)
-		}
-	case bus.MessageDeleted:
-		if c.reactions != nil {
-			c.reactions.HandleMessageDeleted(context.Background(), payload)
-		}
-	case bus.InteractionReceived:
-		c.logger.Debug(
+	bus.Handle(subscriber, func(_ context.Context, event bus.InteractionReceived) {
+		logger.Debug(
 			"discord interaction received",
-			slog.String("interaction_id", payload.InteractionID.String()),
-			slog.Int("type", int(payload.InteractionType)),
+			slog.String("interaction_id", event.InteractionID.String()),
+			slog.Int("type", int(event.InteractionType)),
 		)
-	default:
-		c.logger.Warn("unknown discord event", slog.String("type", fmt.Sprintf("%T", event)))
-	}
-
-	// TODO: use c.app.Dao() to persist events to PocketBase collections or invoke domain services.
+	})
+	subscriber.Start(ctx)
+	return nil
 }
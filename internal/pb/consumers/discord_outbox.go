@@ -0,0 +1,35 @@
+package consumers
+
+import (
+	"context"
+	"log/slog"
+
+	"antartica-bot/internal/bus"
+	"antartica-bot/internal/pb/messages"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+func init() {
+	Register(startDiscordOutboxModule)
+}
+
+// startDiscordOutboxModule writes action results back to the discord_outbox records that requested them.
+func startDiscordOutboxModule(ctx context.Context, app core.App, eventBus *bus.Bus, logger *slog.Logger) error {
+	subscriber, err := eventBus.NewSubscriber("discord_outbox", bus.SubscribeOptions{})
+	if err != nil {
+		return err
+	}
+
+	bus.Handle(subscriber, func(ctx context.Context, result bus.ActionResult) {
+		recordID, ok := messages.DiscordOutboxRecordID(result.Ref)
+		if !ok {
+			return
+		}
+		if err := messages.ApplyDiscordOutboxResult(ctx, app, result); err != nil && logger != nil {
+			logger.Warn("discord outbox result not saved", slog.String("record_id", recordID), slog.Any("err", err))
+		}
+	})
+	subscriber.Start(ctx)
+	return nil
+}
@@ -0,0 +1,243 @@
+package consumers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"antartica-bot/internal/bus"
+
+	"github.com/disgoorg/snowflake/v2"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+const (
+	memberLogCategory = "members"
+
+	memberEventJoin       = "join"
+	memberEventLeave      = "leave"
+	memberEventRoleAdd    = "role_add"
+	memberEventRoleRemove = "role_remove"
+
+	// newAccountAge flags joins from accounts younger than this.
+	newAccountAge = 7 * 24 * time.Hour
+)
+
+type memberHistoryEntry struct {
+	GuildID        snowflake.ID
+	UserID         snowflake.ID
+	Username       string
+	Type           string
+	ActorID        snowflake.ID
+	RoleIDs        []snowflake.ID
+	Reason         string
+	AccountCreated time.Time
+}
+
+// HandleMemberModerated records kicks, bans and unbans reported by the audit log.
+func (p *MemberProcessor) HandleMemberModerated(ctx context.Context, event bus.MemberModerated) {
+	if p == nil || p.app == nil {
+		return
+	}
+
+	p.recordMemberEvent(ctx, memberHistoryEntry{
+		GuildID: event.GuildID,
+		UserID:  event.UserID,
+		Type:    event.Action,
+		ActorID: event.ActorID,
+		Reason:  event.Reason,
+	})
+
+	title := "Member kicked"
+	level := bus.LogWarn
+	switch event.Action {
+	case bus.MemberActionBan:
+		title = "Member banned"
+	case bus.MemberActionUnban:
+		title = "Member unbanned"
+		level = bus.LogInfo
+	}
+
+	fields := []bus.LogField{
+		{Name: "Member", Value: formatMemberMention(event.UserID, ""), Inline: true},
+	}
+	if event.ActorID != 0 {
+		fields = append(fields, bus.LogField{Name: "By", Value: fmt.Sprintf("<@%s>", event.ActorID), Inline: true})
+	}
+	if reason := strings.TrimSpace(event.Reason); reason != "" {
+		fields = append(fields, bus.LogField{Name: "Reason", Value: reason})
+	}
+
+	p.emitLog(bus.LogEvent{
+		GuildID:   event.GuildID,
+		Category:  memberLogCategory,
+		Level:     level,
+		Title:     title,
+		Fields:    fields,
+		Timestamp: time.Now(),
+	})
+}
+
+func (p *MemberProcessor) logMemberJoined(ctx context.Context, event bus.MemberJoined) {
+	p.recordMemberEvent(ctx, memberHistoryEntry{
+		GuildID:        event.GuildID,
+		UserID:         event.UserID,
+		Username:       event.Username,
+		Type:           memberEventJoin,
+		AccountCreated: event.AccountCreatedAt,
+	})
+
+	fields := []bus.LogField{
+		{Name: "Member", Value: formatMemberMention(event.UserID, event.Username), Inline: true},
+	}
+	level := bus.LogInfo
+	if !event.AccountCreatedAt.IsZero() {
+		fields = append(fields, bus.LogField{
+			Name:   "Account created",
+			Value:  fmt.Sprintf("<t:%d:R>", event.AccountCreatedAt.Unix()),
+			Inline: true,
+		})
+		if time.Since(event.AccountCreatedAt) < newAccountAge {
+			level = bus.LogWarn
+			fields = append(fields, bus.LogField{Name: "Note", Value: "New account", Inline: true})
+		}
+	}
+	if event.Bot {
+		fields = append(fields, bus.LogField{Name: "Bot", Value: "yes", Inline: true})
+	}
+
+	p.emitLog(bus.LogEvent{
+		GuildID:   event.GuildID,
+		Category:  memberLogCategory,
+		Level:     level,
+		Title:     "Member joined",
+		Fields:    fields,
+		Timestamp: time.Now(),
+	})
+}
+
+func (p *MemberProcessor) logMemberLeft(ctx context.Context, event bus.MemberLeft) {
+	p.recordMemberEvent(ctx, memberHistoryEntry{
+		GuildID:  event.GuildID,
+		UserID:   event.UserID,
+		Username: event.Username,
+		Type:     memberEventLeave,
+		RoleIDs:  event.RoleIDs,
+	})
+
+	fields := []bus.LogField{
+		{Name: "Member", Value: formatMemberMention(event.UserID, event.Username), Inline: true},
+	}
+	if len(event.RoleIDs) > 0 {
+		fields = append(fields, bus.LogField{Name: "Roles", Value: formatRoleMentions(event.RoleIDs)})
+	}
+
+	p.emitLog(bus.LogEvent{
+		GuildID:   event.GuildID,
+		Category:  memberLogCategory,
+		Level:     bus.LogInfo,
+		Title:     "Member left",
+		Fields:    fields,
+		Timestamp: time.Now(),
+	})
+}
+
+func (p *MemberProcessor) logMemberRoles(ctx context.Context, event bus.MemberUpdated) {
+	if len(event.AddedRoleIDs) == 0 && len(event.RemovedRoleIDs) == 0 {
+		return
+	}
+
+	fields := []bus.LogField{
+		{Name: "Member", Value: formatMemberMention(event.UserID, event.Username), Inline: true},
+	}
+	if len(event.AddedRoleIDs) > 0 {
+		p.recordMemberEvent(ctx, memberHistoryEntry{
+			GuildID:  event.GuildID,
+			UserID:   event.UserID,
+			Username: event.Username,
+			Type:     memberEventRoleAdd,
+			RoleIDs:  event.AddedRoleIDs,
+		})
+		fields = append(fields, bus.LogField{Name: "Added", Value: formatRoleMentions(event.AddedRoleIDs)})
+	}
+	if len(event.RemovedRoleIDs) > 0 {
+		p.recordMemberEvent(ctx, memberHistoryEntry{
+			GuildID:  event.GuildID,
+			UserID:   event.UserID,
+			Username: event.Username,
+			Type:     memberEventRoleRemove,
+			RoleIDs:  event.RemovedRoleIDs,
+		})
+		fields = append(fields, bus.LogField{Name: "Removed", Value: formatRoleMentions(event.RemovedRoleIDs)})
+	}
+
+	p.emitLog(bus.LogEvent{
+		GuildID:   event.GuildID,
+		Category:  memberLogCategory,
+		Level:     bus.LogInfo,
+		Title:     "Member roles changed",
+		Fields:    fields,
+		Timestamp: time.Now(),
+	})
+}
+
+func (p *MemberProcessor) recordMemberEvent(ctx context.Context, entry memberHistoryEntry) {
+	collection, err := p.app.FindCollectionByNameOrId("member_events")
+	if err != nil {
+		if p.logger != nil {
+			p.logger.Warn("member events collection missing", slog.Any("err", err))
+		}
+		return
+	}
+
+	record := core.NewRecord(collection)
+	record.Set("guild_id", entry.GuildID.String())
+	record.Set("user_id", entry.UserID.String())
+	record.Set("username", entry.Username)
+	record.Set("type", entry.Type)
+	if entry.ActorID != 0 {
+		record.Set("actor_id", entry.ActorID.String())
+	}
+	if len(entry.RoleIDs) > 0 {
+		roleIDs := make([]string, 0, len(entry.RoleIDs))
+		for _, roleID := range entry.RoleIDs {
+			roleIDs = append(roleIDs, roleID.String())
+		}
+		record.Set("role_ids", roleIDs)
+	}
+	record.Set("reason", entry.Reason)
+	if !entry.AccountCreated.IsZero() {
+		if created, err := types.ParseDateTime(entry.AccountCreated); err == nil {
+			record.Set("account_created", created)
+		}
+	}
+
+	if err := p.app.SaveWithContext(ctx, record); err != nil && p.logger != nil {
+		p.logger.Warn("member event save failed", slog.String("type", entry.Type), slog.Any("err", err))
+	}
+}
+
+func (p *MemberProcessor) emitLog(event bus.LogEvent) {
+	if p.bus == nil {
+		return
+	}
+	_ = p.bus.PublishAction(context.Background(), event)
+}
+
+func formatMemberMention(userID snowflake.ID, username string) string {
+	if username = strings.TrimSpace(username); username != "" {
+		return fmt.Sprintf("<@%s> (%s)", userID, username)
+	}
+	return fmt.Sprintf("<@%s>", userID)
+}
+
+func formatRoleMentions(roleIDs []snowflake.ID) string {
+	mentions := make([]string, 0, len(roleIDs))
+	for _, roleID := range roleIDs {
+		mentions = append(mentions, fmt.Sprintf("<@&%s>", roleID))
+	}
+	return strings.Join(mentions, " ")
+}
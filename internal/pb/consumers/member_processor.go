@@ -0,0 +1,405 @@
+package consumers
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	"antartica-bot/internal/bus"
+	"antartica-bot/internal/pb/messages"
+	"antartica-bot/internal/pb/stores"
+
+	"github.com/disgoorg/snowflake/v2"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func init() {
+	Register(startMemberModule)
+}
+
+func startMemberModule(ctx context.Context, app core.App, eventBus *bus.Bus, logger *slog.Logger) error {
+	subscriber, err := eventBus.NewSubscriber("members", bus.SubscribeOptions{})
+	if err != nil {
+		return err
+	}
+
+	processor := NewMemberProcessor(app, eventBus, logger, stores.NewAutoRoleStore(app, logger))
+	bus.Handle(subscriber, processor.HandleMemberJoined)
+	bus.Handle(subscriber, processor.HandleMemberUpdated)
+	bus.Handle(subscriber, processor.HandleMemberLeft)
+	bus.Handle(subscriber, processor.HandleMemberModerated)
+	subscriber.Start(ctx)
+	go processor.runScheduledRoles(ctx)
+	return nil
+}
+
+// scheduledRoleInterval is how often delayed auto roles are checked for being due.
+const scheduledRoleInterval = 5 * time.Second
+
+type MemberProcessor struct {
+	app       core.App
+	bus       *bus.Bus
+	logger    *slog.Logger
+	autoRoles bus.AutoRoleStore
+}
+
+func NewMemberProcessor(app core.App, eventBus *bus.Bus, logger *slog.Logger, autoRoles bus.AutoRoleStore) *MemberProcessor {
+	if logger == nil && app != nil {
+		logger = app.Logger()
+	}
+	return &MemberProcessor{
+		app:       app,
+		bus:       eventBus,
+		logger:    logger,
+		autoRoles: autoRoles,
+	}
+}
+
+// HandleMemberJoined logs the join, assigns join roles and restores sticky roles from the member's last snapshot.
+// Join roles that wait for membership screening are skipped while the member is still pending.
+func (p *MemberProcessor) HandleMemberJoined(ctx context.Context, event bus.MemberJoined) {
+	if p == nil || p.app == nil {
+		return
+	}
+
+	p.logMemberJoined(ctx, event)
+	if event.Bot {
+		return
+	}
+
+	autoRoles, err := p.autoRoles.ListAutoRoles(ctx, event.GuildID)
+	if err != nil {
+		if p.logger != nil {
+			p.logger.Warn("auto role lookup failed", slog.Any("err", err))
+		}
+		return
+	}
+	if len(autoRoles) == 0 {
+		return
+	}
+
+	for _, autoRole := range autoRoles {
+		if autoRole.Mode != bus.AutoRoleModeJoin {
+			continue
+		}
+		if autoRole.AfterScreening && event.Pending {
+			continue
+		}
+		p.enqueueRole(ctx, event.GuildID, event.UserID, autoRole, "Auto role on join")
+	}
+
+	p.restoreStickyRoles(ctx, event.GuildID, event.UserID, autoRoles)
+}
+
+// HandleMemberUpdated logs role changes and assigns screening-gated join roles once the member passes membership screening.
+func (p *MemberProcessor) HandleMemberUpdated(ctx context.Context, event bus.MemberUpdated) {
+	if p == nil || p.app == nil {
+		return
+	}
+
+	p.logMemberRoles(ctx, event)
+
+	changed := append(append([]snowflake.ID(nil), event.AddedRoleIDs...), event.RemovedRoleIDs...)
+	p.refreshRoleCounts(ctx, event.GuildID, changed)
+
+	if event.Bot || !event.WasPending || event.Pending {
+		return
+	}
+
+	autoRoles, err := p.autoRoles.ListAutoRoles(ctx, event.GuildID)
+	if err != nil {
+		if p.logger != nil {
+			p.logger.Warn("auto role lookup failed", slog.Any("err", err))
+		}
+		return
+	}
+
+	for _, autoRole := range autoRoles {
+		if autoRole.Mode != bus.AutoRoleModeJoin || !autoRole.AfterScreening {
+			continue
+		}
+		p.enqueueRole(ctx, event.GuildID, event.UserID, autoRole, "Auto role after membership screening")
+	}
+}
+
+// HandleMemberLeft logs the departure and snapshots the departing member's roles when the guild has sticky roles configured.
+func (p *MemberProcessor) HandleMemberLeft(ctx context.Context, event bus.MemberLeft) {
+	if p == nil || p.app == nil {
+		return
+	}
+
+	p.logMemberLeft(ctx, event)
+	p.cancelScheduledRoles(ctx, event.GuildID, event.UserID)
+	if len(event.RoleIDs) == 0 {
+		return
+	}
+
+	p.refreshRoleCounts(ctx, event.GuildID, event.RoleIDs)
+	if event.Bot {
+		return
+	}
+
+	autoRoles, err := p.autoRoles.ListAutoRoles(ctx, event.GuildID)
+	if err != nil {
+		if p.logger != nil {
+			p.logger.Warn("auto role lookup failed", slog.Any("err", err))
+		}
+		return
+	}
+	if !hasStickyRoles(autoRoles) {
+		return
+	}
+
+	roleIDs := make([]string, 0, len(event.RoleIDs))
+	for _, roleID := range event.RoleIDs {
+		roleIDs = append(roleIDs, roleID.String())
+	}
+
+	records, err := p.findSnapshots(event.GuildID, event.UserID)
+	if err != nil {
+		if p.logger != nil {
+			p.logger.Warn("member role snapshot lookup failed", slog.Any("err", err))
+		}
+		return
+	}
+
+	var record *core.Record
+	if len(records) > 0 {
+		record = records[0]
+	} else {
+		collection, err := p.app.FindCollectionByNameOrId("member_role_snapshots")
+		if err != nil {
+			if p.logger != nil {
+				p.logger.Warn("member role snapshots collection missing", slog.Any("err", err))
+			}
+			return
+		}
+		record = core.NewRecord(collection)
+		record.Set("guild_id", event.GuildID.String())
+		record.Set("user_id", event.UserID.String())
+	}
+	record.Set("role_ids", roleIDs)
+
+	if err := p.app.SaveWithContext(ctx, record); err != nil && p.logger != nil {
+		p.logger.Warn("member role snapshot save failed", slog.Any("err", err))
+	}
+}
+
+func (p *MemberProcessor) restoreStickyRoles(ctx context.Context, guildID snowflake.ID, userID snowflake.ID, autoRoles []bus.AutoRole) {
+	if !hasStickyRoles(autoRoles) {
+		return
+	}
+
+	records, err := p.findSnapshots(guildID, userID)
+	if err != nil {
+		if p.logger != nil {
+			p.logger.Warn("member role snapshot lookup failed", slog.Any("err", err))
+		}
+		return
+	}
+	if len(records) == 0 {
+		return
+	}
+
+	snapshot := make(map[snowflake.ID]struct{})
+	for _, record := range records {
+		for _, roleID := range snapshotRoleIDs(record) {
+			snapshot[roleID] = struct{}{}
+		}
+	}
+
+	for _, autoRole := range autoRoles {
+		if autoRole.Mode != bus.AutoRoleModeSticky {
+			continue
+		}
+		if _, ok := snapshot[autoRole.RoleID]; !ok {
+			continue
+		}
+		p.enqueueRole(ctx, guildID, userID, autoRole, "Sticky role restored on rejoin")
+	}
+
+	for _, record := range records {
+		if err := p.app.DeleteWithContext(ctx, record); err != nil && p.logger != nil {
+			p.logger.Warn("member role snapshot delete failed", slog.Any("err", err))
+		}
+	}
+}
+
+// refreshRoleCounts updates role list messages showing member counts when a self-assignable role changed hands.
+func (p *MemberProcessor) refreshRoleCounts(ctx context.Context, guildID snowflake.ID, roleIDs []snowflake.ID) {
+	if p.bus == nil || len(roleIDs) == 0 {
+		return
+	}
+
+	values := make([]any, 0, len(roleIDs))
+	for _, roleID := range roleIDs {
+		values = append(values, roleID.String())
+	}
+	records, err := p.app.FindAllRecords("role_toggles",
+		dbx.HashExp{"guild_id": guildID.String()},
+		dbx.In("role_id", values...),
+	)
+	if err != nil {
+		if p.logger != nil {
+			p.logger.Warn("role toggle lookup failed", slog.Any("err", err))
+		}
+		return
+	}
+	if len(records) == 0 {
+		return
+	}
+
+	if err := messages.EnqueueRoleToggleCountUpdates(ctx, p.app, p.bus, p.logger, guildID.String()); err != nil && p.logger != nil {
+		p.logger.Warn("role toggle count update failed", slog.Any("err", err))
+	}
+}
+
+// enqueueRole adds the role now, or stores it in scheduled_roles until its delay has passed.
+func (p *MemberProcessor) enqueueRole(ctx context.Context, guildID snowflake.ID, userID snowflake.ID, autoRole bus.AutoRole, reason string) {
+	if p.bus == nil {
+		return
+	}
+
+	if autoRole.Delay <= 0 {
+		_ = p.bus.PublishAction(ctx, bus.AddMemberRole{
+			GuildID: guildID,
+			UserID:  userID,
+			RoleID:  autoRole.RoleID,
+			Reason:  reason,
+		})
+		return
+	}
+
+	collection, err := p.app.FindCollectionByNameOrId("scheduled_roles")
+	if err != nil {
+		if p.logger != nil {
+			p.logger.Warn("scheduled roles collection missing", slog.Any("err", err))
+		}
+		return
+	}
+	record := core.NewRecord(collection)
+	record.Set("guild_id", guildID.String())
+	record.Set("user_id", userID.String())
+	record.Set("role_id", autoRole.RoleID.String())
+	record.Set("reason", reason)
+	record.Set("due_at", time.Now().Add(autoRole.Delay).UTC())
+	if err := p.app.SaveWithContext(ctx, record); err != nil && p.logger != nil {
+		p.logger.Warn("scheduled role save failed", slog.Any("err", err))
+	}
+}
+
+// runScheduledRoles publishes delayed auto roles once they are due, including ones scheduled before a restart.
+func (p *MemberProcessor) runScheduledRoles(ctx context.Context) {
+	ticker := time.NewTicker(scheduledRoleInterval)
+	defer ticker.Stop()
+
+	for {
+		p.publishDueRoles(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// publishDueRoles removes each due role from the schedule only after it was queued, so one that can't be
+// published is retried on the next pass or the next start.
+func (p *MemberProcessor) publishDueRoles(ctx context.Context) {
+	if p.bus == nil {
+		return
+	}
+
+	records, err := p.app.FindRecordsByFilter("scheduled_roles", "due_at <= {:now}", "due_at", 100, 0, dbx.Params{
+		"now": types.NowDateTime().String(),
+	})
+	if err != nil {
+		if p.logger != nil {
+			p.logger.Warn("scheduled role lookup failed", slog.Any("err", err))
+		}
+		return
+	}
+
+	for _, record := range records {
+		if ctx.Err() != nil {
+			return
+		}
+
+		guildID, guildErr := snowflake.Parse(record.GetString("guild_id"))
+		userID, userErr := snowflake.Parse(record.GetString("user_id"))
+		roleID, roleErr := snowflake.Parse(record.GetString("role_id"))
+		if guildErr == nil && userErr == nil && roleErr == nil {
+			err := p.bus.PublishAction(ctx, bus.AddMemberRole{
+				GuildID: guildID,
+				UserID:  userID,
+				RoleID:  roleID,
+				Reason:  record.GetString("reason"),
+			})
+			if err != nil {
+				if p.logger != nil {
+					p.logger.Warn("scheduled role publish failed", slog.Any("err", err))
+				}
+				return
+			}
+		}
+
+		if err := p.app.DeleteWithContext(ctx, record); err != nil && p.logger != nil {
+			p.logger.Warn("scheduled role delete failed", slog.Any("err", err))
+		}
+	}
+}
+
+// cancelScheduledRoles drops roles still waiting for a member who left.
+func (p *MemberProcessor) cancelScheduledRoles(ctx context.Context, guildID snowflake.ID, userID snowflake.ID) {
+	records, err := p.app.FindAllRecords("scheduled_roles", dbx.HashExp{
+		"guild_id": guildID.String(),
+		"user_id":  userID.String(),
+	})
+	if err != nil {
+		if p.logger != nil {
+			p.logger.Warn("scheduled role lookup failed", slog.Any("err", err))
+		}
+		return
+	}
+	for _, record := range records {
+		if err := p.app.DeleteWithContext(ctx, record); err != nil && p.logger != nil {
+			p.logger.Warn("scheduled role delete failed", slog.Any("err", err))
+		}
+	}
+}
+
+func (p *MemberProcessor) findSnapshots(guildID snowflake.ID, userID snowflake.ID) ([]*core.Record, error) {
+	return p.app.FindAllRecords("member_role_snapshots", dbx.HashExp{
+		"guild_id": guildID.String(),
+		"user_id":  userID.String(),
+	})
+}
+
+func snapshotRoleIDs(record *core.Record) []snowflake.ID {
+	var raw []string
+	if err := record.UnmarshalJSONField("role_ids", &raw); err != nil {
+		return nil
+	}
+
+	roleIDs := make([]snowflake.ID, 0, len(raw))
+	for _, value := range raw {
+		roleID, err := snowflake.Parse(strings.TrimSpace(value))
+		if err != nil {
+			continue
+		}
+		roleIDs = append(roleIDs, roleID)
+	}
+	return roleIDs
+}
+
+func hasStickyRoles(autoRoles []bus.AutoRole) bool {
+	for _, autoRole := range autoRoles {
+		if autoRole.Mode == bus.AutoRoleModeSticky {
+			return true
+		}
+	}
+	return false
+}
@@ -6,24 +6,47 @@ import (
 	"strings"
 
 	"antartica-bot/internal/bus"
+	"antartica-bot/internal/settings"
 
 	"github.com/disgoorg/snowflake/v2"
 	"github.com/pocketbase/dbx"
 	"github.com/pocketbase/pocketbase/core"
 )
 
+func init() {
+	Register(startReactionModule)
+}
+
+func startReactionModule(ctx context.Context, app core.App, eventBus *bus.Bus, logger *slog.Logger) error {
+	subscriber, err := eventBus.NewSubscriber("reactions", bus.SubscribeOptions{})
+	if err != nil {
+		return err
+	}
+
+	processor := NewReactionProcessor(app, logger, eventBus.GuildSettings)
+	bus.Handle(subscriber, processor.HandleReactionAdd)
+	bus.Handle(subscriber, processor.HandleReactionRemove)
+	bus.Handle(subscriber, processor.HandleReactionRemoveEmoji)
+	bus.Handle(subscriber, processor.HandleReactionRemoveAll)
+	bus.Handle(subscriber, processor.HandleMessageDeleted)
+	subscriber.Start(ctx)
+	return nil
+}
+
 type ReactionProcessor struct {
-	app    core.App
-	logger *slog.Logger
+	app      core.App
+	logger   *slog.Logger
+	settings bus.GuildSettingsStore
 }
 
-func NewReactionProcessor(app core.App, logger *slog.Logger) *ReactionProcessor {
+func NewReactionProcessor(app core.App, logger *slog.Logger, guildSettings bus.GuildSettingsStore) *ReactionProcessor {
 	if logger == nil && app != nil {
 		logger = app.Logger()
 	}
 	return &ReactionProcessor{
-		app:    app,
-		logger: logger,
+		app:      app,
+		logger:   logger,
+		settings: guildSettings,
 	}
 }
 
@@ -44,7 +67,8 @@ func (p *ReactionProcessor) HandleReactionAdd(ctx context.Context, event bus.Rea
 		return
 	}
 
-	if event.AuthorID != 0 && event.AuthorID == event.UserID {
+	countSelf := p.countSelfReactions(ctx, event.GuildID)
+	if event.AuthorID != 0 && event.AuthorID == event.UserID && !countSelf {
 		return
 	}
 
@@ -59,7 +83,7 @@ func (p *ReactionProcessor) HandleReactionAdd(ctx context.Context, event bus.Rea
 	if len(records) > 0 {
 		record := records[0]
 		authorID := strings.TrimSpace(record.GetString("user_id"))
-		if authorID == "" || authorID == event.UserID.String() {
+		if authorID == "" || (authorID == event.UserID.String() && !countSelf) {
 			return
 		}
 
@@ -80,7 +104,7 @@ func (p *ReactionProcessor) HandleReactionAdd(ctx context.Context, event bus.Rea
 	}
 
 	authorID := event.AuthorID
-	if authorID == 0 || authorID == event.UserID {
+	if authorID == 0 || (authorID == event.UserID && !countSelf) {
 		return
 	}
 
@@ -127,7 +151,7 @@ func (p *ReactionProcessor) HandleReactionRemove(ctx context.Context, event bus.
 
 	record := records[0]
 	authorID := strings.TrimSpace(record.GetString("user_id"))
-	if authorID == "" || authorID == event.UserID.String() {
+	if authorID == "" || (authorID == event.UserID.String() && !p.countSelfReactions(ctx, event.GuildID)) {
 		return
 	}
 
@@ -206,6 +230,16 @@ func (p *ReactionProcessor) HandleMessageDeleted(ctx context.Context, event bus.
 	}
 }
 
+// countSelfReactions reports whether the guild counts reactions on members' own messages.
+// Changing the setting doesn't touch existing counts, so removing a reaction added under the old setting may skew them slightly.
+func (p *ReactionProcessor) countSelfReactions(ctx context.Context, guildID snowflake.ID) bool {
+	guild, err := settings.Load(ctx, p.settings, guildID)
+	if err != nil && p.logger != nil {
+		p.logger.Warn("guild settings lookup failed", slog.Any("err", err))
+	}
+	return guild.Bool(settings.ReactionsCountSelf)
+}
+
 func (p *ReactionProcessor) isTrackedReaction(_ context.Context, guildID snowflake.ID, emojiID string, emojiName string) (bool, error) {
 	if emojiID == "" && emojiName == "" {
 		return false, nil
@@ -0,0 +1,236 @@
+package consumers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"antartica-bot/internal/bus"
+	"antartica-bot/internal/pb/messages"
+
+	"github.com/disgoorg/snowflake/v2"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+func init() {
+	Register(startRoleModule)
+}
+
+func startRoleModule(ctx context.Context, app core.App, eventBus *bus.Bus, logger *slog.Logger) error {
+	subscriber, err := eventBus.NewSubscriber("roles", bus.SubscribeOptions{})
+	if err != nil {
+		return err
+	}
+
+	processor := NewRoleProcessor(app, eventBus, logger)
+	bus.Handle(subscriber, processor.HandleRoleUpdated)
+	bus.Handle(subscriber, processor.HandleRolesUpdated)
+	bus.Handle(subscriber, processor.HandleRoleDeleted)
+	subscriber.Start(ctx)
+	return nil
+}
+
+type RoleProcessor struct {
+	app    core.App
+	bus    *bus.Bus
+	logger *slog.Logger
+
+	flagged   map[snowflake.ID]bool
+	flaggedMu sync.Mutex
+}
+
+func NewRoleProcessor(app core.App, eventBus *bus.Bus, logger *slog.Logger) *RoleProcessor {
+	if logger == nil && app != nil {
+		logger = app.Logger()
+	}
+	return &RoleProcessor{
+		app:     app,
+		bus:     eventBus,
+		logger:  logger,
+		flagged: make(map[snowflake.ID]bool),
+	}
+}
+
+// HandleRoleDeleted removes role toggles and auto roles for a deleted Discord role.
+// Deleting the role toggle rows triggers the role list refresh through the record hooks.
+func (p *RoleProcessor) HandleRoleDeleted(ctx context.Context, event bus.RoleDeleted) {
+	if p == nil || p.app == nil {
+		return
+	}
+
+	p.clearFlag(event.RoleID)
+
+	removed := 0
+	for _, collection := range []string{"role_toggles", "auto_roles"} {
+		records, err := p.app.FindAllRecords(collection, dbx.HashExp{
+			"guild_id": event.GuildID.String(),
+			"role_id":  event.RoleID.String(),
+		})
+		if err != nil {
+			if p.logger != nil {
+				p.logger.Warn("deleted role lookup failed", slog.String("collection", collection), slog.Any("err", err))
+			}
+			continue
+		}
+		for _, record := range records {
+			if err := p.app.DeleteWithContext(ctx, record); err != nil {
+				if p.logger != nil {
+					p.logger.Warn("deleted role cleanup failed", slog.String("collection", collection), slog.Any("err", err))
+				}
+				continue
+			}
+			removed++
+		}
+	}
+
+	if removed == 0 {
+		return
+	}
+
+	if p.logger != nil {
+		p.logger.Info(
+			"deleted role cleaned up",
+			slog.String("guild_id", event.GuildID.String()),
+			slog.String("role_id", event.RoleID.String()),
+			slog.Int("count", removed),
+		)
+	}
+	p.emitLog(bus.LogEvent{
+		GuildID:     event.GuildID,
+		Category:    "roles",
+		Level:       bus.LogInfo,
+		Title:       "Deleted role removed from configuration",
+		Description: "A role used by role toggles or auto roles was deleted from the server.",
+		Fields: []bus.LogField{
+			{Name: "Role ID", Value: event.RoleID.String(), Inline: true},
+			{Name: "Entries removed", Value: fmt.Sprintf("%d", removed), Inline: true},
+		},
+		Timestamp: time.Now(),
+	})
+}
+
+// HandleRoleUpdated flags configured roles the bot can no longer manage, once per transition.
+// Role list messages are refreshed as well, since they may be ordered by role position.
+func (p *RoleProcessor) HandleRoleUpdated(ctx context.Context, event bus.RoleUpdated) {
+	if p == nil || p.app == nil {
+		return
+	}
+
+	p.refreshRoleLists(ctx, event.GuildID, dbx.HashExp{"role_id": event.RoleID.String()})
+	p.checkManageable(event)
+}
+
+// HandleRolesUpdated handles a guild-wide re-check after the bot's role changed. Role list messages are
+// refreshed once for the guild, however many roles changed.
+func (p *RoleProcessor) HandleRolesUpdated(ctx context.Context, event bus.RolesUpdated) {
+	if p == nil || p.app == nil {
+		return
+	}
+
+	p.refreshRoleLists(ctx, event.GuildID, nil)
+	for _, role := range event.Roles {
+		p.checkManageable(role)
+	}
+}
+
+// refreshRoleLists re-renders the guild's role list messages when it has role toggles matching filter.
+// A nil filter matches any toggle in the guild.
+func (p *RoleProcessor) refreshRoleLists(ctx context.Context, guildID snowflake.ID, filter dbx.HashExp) {
+	exp := dbx.HashExp{"guild_id": guildID.String()}
+	for column, value := range filter {
+		exp[column] = value
+	}
+
+	toggles, err := p.app.FindAllRecords("role_toggles", exp)
+	if err != nil {
+		if p.logger != nil {
+			p.logger.Warn("role toggle lookup failed", slog.Any("err", err))
+		}
+		return
+	}
+	if len(toggles) == 0 || p.bus == nil {
+		return
+	}
+	if err := messages.EnqueueRoleToggleUpdates(ctx, p.app, p.bus, p.logger, guildID.String()); err != nil && p.logger != nil {
+		p.logger.Warn("role toggle message update failed", slog.Any("err", err))
+	}
+}
+
+// checkManageable flags a configured role the bot can no longer manage, once per transition.
+func (p *RoleProcessor) checkManageable(event bus.RoleUpdated) {
+	if event.Manageable {
+		p.clearFlag(event.RoleID)
+		return
+	}
+
+	configured, err := p.isConfiguredRole(event.GuildID, event.RoleID)
+	if err != nil {
+		if p.logger != nil {
+			p.logger.Warn("role configuration lookup failed", slog.Any("err", err))
+		}
+		return
+	}
+	if !configured || !p.setFlag(event.RoleID) {
+		return
+	}
+
+	reason := strings.TrimSpace(event.Reason)
+	if reason == "" {
+		reason = "Bot cannot manage that role."
+	}
+	p.emitLog(bus.LogEvent{
+		GuildID:     event.GuildID,
+		Category:    "roles",
+		Level:       bus.LogWarn,
+		Title:       "Configured role is no longer manageable",
+		Description: fmt.Sprintf("<@&%s> is used by role toggles or auto roles, but the bot can no longer assign it. Move the bot's role above it or remove it from the configuration.", event.RoleID.String()),
+		Fields: []bus.LogField{
+			{Name: "Role", Value: event.Name, Inline: true},
+			{Name: "Reason", Value: reason, Inline: true},
+		},
+		Timestamp: time.Now(),
+	})
+}
+
+func (p *RoleProcessor) isConfiguredRole(guildID snowflake.ID, roleID snowflake.ID) (bool, error) {
+	for _, collection := range []string{"role_toggles", "auto_roles"} {
+		records, err := p.app.FindAllRecords(collection, dbx.HashExp{
+			"guild_id": guildID.String(),
+			"role_id":  roleID.String(),
+		})
+		if err != nil {
+			return false, err
+		}
+		if len(records) > 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (p *RoleProcessor) setFlag(roleID snowflake.ID) bool {
+	p.flaggedMu.Lock()
+	defer p.flaggedMu.Unlock()
+	if p.flagged[roleID] {
+		return false
+	}
+	p.flagged[roleID] = true
+	return true
+}
+
+func (p *RoleProcessor) clearFlag(roleID snowflake.ID) {
+	p.flaggedMu.Lock()
+	delete(p.flagged, roleID)
+	p.flaggedMu.Unlock()
+}
+
+func (p *RoleProcessor) emitLog(event bus.LogEvent) {
+	if p.bus == nil {
+		return
+	}
+	_ = p.bus.PublishAction(context.Background(), event)
+}
@@ -68,6 +68,15 @@ func RegisterHooks(app *pocketbase.PocketBase, eventBus *bus.Bus, logger *slog.L
 		return e.Next()
 	})
 
+	app.OnRecordAfterCreateSuccess("discord_outbox").BindFunc(func(e *core.RecordEvent) error {
+		if eventBus != nil {
+			if err := messages.EnqueueDiscordOutbox(context.Background(), e.App, eventBus, logger, e.Record); err != nil {
+				logger.Warn("discord outbox enqueue failed", slog.String("record_id", e.Record.Id), slog.Any("err", err))
+			}
+		}
+		return e.Next()
+	})
+
 	app.OnRecordAfterUpdateSuccess("role_toggles").BindFunc(func(e *core.RecordEvent) error {
 		if eventBus != nil {
 			if err := messages.EnqueueRoleToggleUpdates(context.Background(), e.App, eventBus, logger, e.Record.GetString("guild_id")); err != nil {
@@ -0,0 +1,168 @@
+package messages
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"antartica-bot/internal/bus"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/snowflake/v2"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+const (
+	DiscordOutboxStatusPending    = "pending"
+	DiscordOutboxStatusQueued     = "queued"
+	DiscordOutboxStatusSent       = "sent"
+	DiscordOutboxStatusFailed     = "failed"
+	DiscordOutboxStatusSuperseded = "superseded"
+
+	// discordOutboxRefPrefix marks action results that belong to a discord_outbox record.
+	discordOutboxRefPrefix = "discord_outbox:"
+
+	maxDiscordOutboxEmbeds = 10
+)
+
+// EnqueueDiscordOutbox turns a new discord_outbox record into a send or edit action.
+// Records that can't be sent are marked failed with the reason.
+func EnqueueDiscordOutbox(ctx context.Context, app core.App, eventBus *bus.Bus, logger *slog.Logger, record *core.Record) error {
+	if app == nil || eventBus == nil || record == nil {
+		return nil
+	}
+	status := strings.TrimSpace(record.GetString("status"))
+	if status != "" && status != DiscordOutboxStatusPending {
+		return nil
+	}
+
+	action, err := buildDiscordOutboxAction(record)
+	if err != nil {
+		return markDiscordOutbox(ctx, app, record, DiscordOutboxStatusFailed, err.Error())
+	}
+
+	// Mark the record before publishing so a fast result isn't overwritten.
+	if err := markDiscordOutbox(ctx, app, record, DiscordOutboxStatusQueued, ""); err != nil {
+		return err
+	}
+	if err := eventBus.PublishAction(ctx, action); err != nil {
+		if logger != nil {
+			logger.Warn("discord outbox action not queued", slog.String("record_id", record.Id), slog.Any("err", err))
+		}
+		return markDiscordOutbox(ctx, app, record, DiscordOutboxStatusFailed, err.Error())
+	}
+	return nil
+}
+
+// DiscordOutboxRecordID returns the discord_outbox record an action result belongs to.
+func DiscordOutboxRecordID(ref string) (string, bool) {
+	id, ok := strings.CutPrefix(ref, discordOutboxRefPrefix)
+	return id, ok && id != ""
+}
+
+// ApplyDiscordOutboxResult writes the message ID and final status back to the record.
+func ApplyDiscordOutboxResult(ctx context.Context, app core.App, result bus.ActionResult) error {
+	recordID, ok := DiscordOutboxRecordID(result.Ref)
+	if !ok || app == nil {
+		return nil
+	}
+
+	record, err := app.FindRecordById("discord_outbox", recordID)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case result.Error != "":
+		return markDiscordOutbox(ctx, app, record, DiscordOutboxStatusFailed, result.Error)
+	case result.Superseded:
+		return markDiscordOutbox(ctx, app, record, DiscordOutboxStatusSuperseded, "")
+	default:
+		if result.MessageID != 0 {
+			record.Set("message_id", result.MessageID.String())
+		}
+		return markDiscordOutbox(ctx, app, record, DiscordOutboxStatusSent, "")
+	}
+}
+
+func buildDiscordOutboxAction(record *core.Record) (bus.DiscordAction, error) {
+	channelID, err := snowflake.Parse(strings.TrimSpace(record.GetString("channel_id")))
+	if err != nil {
+		return nil, errors.New("channel_id is not a valid ID")
+	}
+
+	content := strings.TrimSpace(record.GetString("content"))
+	embeds, err := parseDiscordOutboxEmbeds(record)
+	if err != nil {
+		return nil, err
+	}
+	if content == "" && len(embeds) == 0 {
+		return nil, errors.New("content or embeds is required")
+	}
+
+	ref := discordOutboxRefPrefix + record.Id
+
+	if raw := strings.TrimSpace(record.GetString("edit_message_id")); raw != "" {
+		messageID, err := snowflake.Parse(raw)
+		if err != nil {
+			return nil, errors.New("edit_message_id is not a valid ID")
+		}
+		return bus.EditMessage{
+			ChannelID: channelID,
+			MessageID: messageID,
+			Content:   content,
+			Embeds:    embeds,
+			Reply:     bus.Reply{Ref: ref},
+		}, nil
+	}
+
+	action := bus.SendMessage{
+		ChannelID: channelID,
+		Content:   content,
+		Embeds:    embeds,
+		Reply:     bus.Reply{Ref: ref},
+	}
+	if raw := strings.TrimSpace(record.GetString("reply_to")); raw != "" {
+		replyTo, err := snowflake.Parse(raw)
+		if err != nil {
+			return nil, errors.New("reply_to is not a valid ID")
+		}
+		action.ReplyTo = replyTo
+	}
+	return action, nil
+}
+
+// parseDiscordOutboxEmbeds accepts either a single embed object or an array of embeds.
+func parseDiscordOutboxEmbeds(record *core.Record) ([]discord.Embed, error) {
+	raw, _ := record.Get("embeds").(types.JSONRaw)
+	trimmed := strings.TrimSpace(string(raw))
+	if trimmed == "" || trimmed == "null" {
+		return nil, nil
+	}
+
+	var embeds []discord.Embed
+	if strings.HasPrefix(trimmed, "{") {
+		var embed discord.Embed
+		if err := json.Unmarshal([]byte(trimmed), &embed); err != nil {
+			return nil, fmt.Errorf("embeds is not a valid embed: %w", err)
+		}
+		embeds = []discord.Embed{embed}
+	} else if err := json.Unmarshal([]byte(trimmed), &embeds); err != nil {
+		return nil, fmt.Errorf("embeds is not a valid embed list: %w", err)
+	}
+
+	if len(embeds) > maxDiscordOutboxEmbeds {
+		return nil, fmt.Errorf("embeds has %d entries, Discord allows %d", len(embeds), maxDiscordOutboxEmbeds)
+	}
+	return embeds, nil
+}
+
+func markDiscordOutbox(ctx context.Context, app core.App, record *core.Record, status string, reason string) error {
+	record.Set("status", status)
+	record.Set("error", reason)
+	return app.SaveWithContext(ctx, record)
+}
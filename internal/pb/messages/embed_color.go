@@ -0,0 +1,34 @@
+package messages
+
+import (
+	"context"
+	"log/slog"
+
+	"antartica-bot/internal/bus"
+	"antartica-bot/internal/settings"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/snowflake/v2"
+)
+
+// applyGuildEmbedColor recolours a managed embed with the guild's embed.color setting, if set.
+func applyGuildEmbedColor(ctx context.Context, eventBus *bus.Bus, logger *slog.Logger, guildID string, embed *discord.Embed) {
+	if eventBus == nil || eventBus.GuildSettings == nil {
+		return
+	}
+	id, err := snowflake.Parse(guildID)
+	if err != nil {
+		return
+	}
+
+	guild, err := settings.Load(ctx, eventBus.GuildSettings, id)
+	if err != nil {
+		if logger != nil {
+			logger.Warn("guild settings lookup failed", slog.Any("err", err))
+		}
+		return
+	}
+	if color, ok := guild.Color(settings.EmbedColor); ok {
+		embed.Color = color
+	}
+}
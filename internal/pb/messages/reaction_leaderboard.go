@@ -7,6 +7,7 @@ import (
 	"log/slog"
 	"sort"
 	"strings"
+	"sync/atomic"
 
 	"antartica-bot/internal/bus"
 	discordembed "antartica-bot/internal/discord/embeds"
@@ -22,6 +23,20 @@ const (
 	StaticMessageUpdateInstant   = "instant"
 )
 
+var defaultLeaderboardTop atomic.Int64
+
+func init() {
+	defaultLeaderboardTop.Store(10)
+}
+
+// SetDefaultLeaderboardTop sets how many members a leaderboard shows when its config has no top.
+// Values below 1 are ignored.
+func SetDefaultLeaderboardTop(top int) {
+	if top > 0 {
+		defaultLeaderboardTop.Store(int64(top))
+	}
+}
+
 type leaderboardConfig struct {
 	EmojiID   string `json:"emoji_id,omitempty"`
 	EmojiName string `json:"emoji_name,omitempty"`
@@ -161,12 +176,13 @@ func EnqueueLeaderboardUpdates(ctx context.Context, app core.App, eventBus *bus.
 			}
 			continue
 		}
+		applyGuildEmbedColor(ctx, eventBus, logger, guildID, &embed)
 
-		eventBus.DiscordActions <- bus.EditMessage{
+		_ = eventBus.PublishAction(ctx, bus.EditMessage{
 			ChannelID: channelID,
 			MessageID: messageID,
 			Embeds:    []discord.Embed{embed},
-		}
+		})
 	}
 
 	return nil
@@ -210,7 +226,7 @@ func buildLeaderboardEmbed(ctx context.Context, app core.App, guildID string, co
 
 	top := config.Top
 	if top <= 0 {
-		top = 10
+		top = int(defaultLeaderboardTop.Load())
 	}
 
 	title := strings.TrimSpace(config.Title)
@@ -9,8 +9,8 @@ import (
 	"strings"
 
 	"antartica-bot/internal/bus"
-	discordembed "antartica-bot/internal/discord/embeds"
 	"antartica-bot/internal/discord/commands"
+	discordembed "antartica-bot/internal/discord/embeds"
 
 	"github.com/disgoorg/disgo/discord"
 	"github.com/disgoorg/snowflake/v2"
@@ -20,12 +20,52 @@ import (
 
 const StaticMessageTypeRoleToggles = "role_toggles"
 
+const (
+	RoleListOrderPosition = "position"
+	RoleListOrderCustom   = "custom"
+	RoleListOrderName     = "name"
+)
+
+// Discord embed limits. The total is kept below the hard 6000 character cap to leave room for the title and description.
+const (
+	embedMaxFields     = 25
+	embedMaxFieldValue = 1024
+	embedMaxFieldName  = 256
+	embedMaxFieldTotal = 5000
+)
+
+const uncategorizedRoleField = "Roles"
+
 type roleToggleMessageConfig struct {
 	Title       string `json:"title,omitempty"`
 	Description string `json:"description,omitempty"`
+	Order       string `json:"order,omitempty"`
+	HideEmoji   bool   `json:"hide_emoji,omitempty"`
+	ShowCounts  bool   `json:"show_counts,omitempty"`
+}
+
+type roleToggleEntry struct {
+	RoleID      string
+	Description string
+	Category    string
+	Emoji       string
+	SortOrder   int
+	Name        string
+	Position    int
+	MemberCount int
+	HasCount    bool
 }
 
 func EnqueueRoleToggleUpdates(ctx context.Context, app core.App, eventBus *bus.Bus, logger *slog.Logger, guildID string) error {
+	return enqueueRoleToggleUpdates(ctx, app, eventBus, logger, guildID, false)
+}
+
+// EnqueueRoleToggleCountUpdates refreshes only the role list messages that display member counts.
+func EnqueueRoleToggleCountUpdates(ctx context.Context, app core.App, eventBus *bus.Bus, logger *slog.Logger, guildID string) error {
+	return enqueueRoleToggleUpdates(ctx, app, eventBus, logger, guildID, true)
+}
+
+func enqueueRoleToggleUpdates(ctx context.Context, app core.App, eventBus *bus.Bus, logger *slog.Logger, guildID string, countsOnly bool) error {
 	if app == nil || eventBus == nil {
 		return nil
 	}
@@ -58,26 +98,30 @@ func EnqueueRoleToggleUpdates(ctx context.Context, app core.App, eventBus *bus.B
 		if err != nil && logger != nil {
 			logger.Warn("invalid role toggle message config", slog.Any("err", err))
 		}
+		if countsOnly && !config.ShowCounts {
+			continue
+		}
 
-		embed, err := buildRoleToggleEmbed(ctx, app, guildID, config)
+		embed, err := buildRoleToggleEmbed(ctx, app, eventBus.RoleDirectory, guildID, config)
 		if err != nil {
 			if logger != nil {
 				logger.Warn("failed to build role toggle embed", slog.Any("err", err))
 			}
 			continue
 		}
+		applyGuildEmbedColor(ctx, eventBus, logger, guildID, &embed)
 
-		eventBus.DiscordActions <- bus.EditMessage{
+		_ = eventBus.PublishAction(ctx, bus.EditMessage{
 			ChannelID: channelID,
 			MessageID: messageID,
 			Embeds:    []discord.Embed{embed},
-		}
+		})
 	}
 
 	return nil
 }
 
-func buildRoleToggleEmbed(_ context.Context, app core.App, guildID string, config roleToggleMessageConfig) (discord.Embed, error) {
+func buildRoleToggleEmbed(ctx context.Context, app core.App, directory bus.RoleDirectory, guildID string, config roleToggleMessageConfig) (discord.Embed, error) {
 	if app == nil {
 		return discord.Embed{}, nil
 	}
@@ -91,27 +135,28 @@ func buildRoleToggleEmbed(_ context.Context, app core.App, guildID string, confi
 		description = fmt.Sprintf("Use `/%s` to add or remove roles.", commands.RoleSelfCommandName)
 	}
 
-	lines, err := loadRoleToggleLines(app, guildID)
+	entries, err := loadRoleToggleEntries(ctx, app, directory, guildID)
 	if err != nil {
 		return discord.Embed{}, err
 	}
 
-	rolesValue := "No self-assignable roles are configured."
-	if len(lines) > 0 {
-		rolesValue = strings.Join(lines, "\n")
+	fields := []discord.EmbedField{
+		{Name: uncategorizedRoleField, Value: "No self-assignable roles are configured."},
+	}
+	if len(entries) > 0 {
+		sortRoleToggleEntries(entries, config.Order)
+		fields = buildRoleToggleFields(entries, config)
 	}
 
 	return discordembed.BuildEmbed(discordembed.EmbedTemplate{
 		Tone:        discordembed.EmbedInfo,
 		Title:       title,
 		Description: description,
-		Fields: []discord.EmbedField{
-			{Name: "Roles", Value: rolesValue},
-		},
+		Fields:      fields,
 	}), nil
 }
 
-func loadRoleToggleLines(app core.App, guildID string) ([]string, error) {
+func loadRoleToggleEntries(ctx context.Context, app core.App, directory bus.RoleDirectory, guildID string) ([]roleToggleEntry, error) {
 	if app == nil {
 		return nil, nil
 	}
@@ -123,9 +168,12 @@ func loadRoleToggleLines(app core.App, guildID string) ([]string, error) {
 		return nil, err
 	}
 
-	type roleToggleEntry struct {
-		RoleID      string
-		Description string
+	var roles map[snowflake.ID]bus.GuildRole
+	if directory != nil && len(records) > 0 {
+		if parsedGuildID, err := snowflake.Parse(guildID); err == nil {
+			// Missing Discord data only degrades ordering and counts, so the list is still rendered.
+			roles, _ = directory.GuildRoles(ctx, parsedGuildID)
+		}
 	}
 
 	entries := make([]roleToggleEntry, 0, len(records))
@@ -134,26 +182,157 @@ func loadRoleToggleLines(app core.App, guildID string) ([]string, error) {
 		if roleID == "" {
 			continue
 		}
-		entries = append(entries, roleToggleEntry{
+		entry := roleToggleEntry{
 			RoleID:      roleID,
 			Description: strings.TrimSpace(record.GetString("description")),
-		})
+			Category:    strings.TrimSpace(record.GetString("category")),
+			Emoji:       strings.TrimSpace(record.GetString("emoji")),
+			SortOrder:   record.GetInt("sort_order"),
+		}
+		if parsedRoleID, err := snowflake.Parse(roleID); err == nil && roles != nil {
+			role, ok := roles[parsedRoleID]
+			if !ok {
+				// The role no longer exists in Discord; the role delete handler cleans these up.
+				continue
+			}
+			entry.Name = role.Name
+			entry.Position = role.Position
+			entry.MemberCount = role.MemberCount
+			entry.HasCount = role.MemberCountKnown
+		}
+		entries = append(entries, entry)
 	}
 
-	sort.Slice(entries, func(i, j int) bool {
+	return entries, nil
+}
+
+func sortRoleToggleEntries(entries []roleToggleEntry, order string) {
+	byID := func(i, j int) bool {
 		return entries[i].RoleID < entries[j].RoleID
-	})
+	}
+
+	switch strings.TrimSpace(order) {
+	case RoleListOrderCustom:
+		sort.SliceStable(entries, func(i, j int) bool {
+			if entries[i].SortOrder == entries[j].SortOrder {
+				return byID(i, j)
+			}
+			return entries[i].SortOrder < entries[j].SortOrder
+		})
+	case RoleListOrderName:
+		sort.SliceStable(entries, func(i, j int) bool {
+			left := strings.ToLower(entries[i].Name)
+			right := strings.ToLower(entries[j].Name)
+			if left == right {
+				return byID(i, j)
+			}
+			return left < right
+		})
+	default:
+		// Discord lists higher positions first.
+		sort.SliceStable(entries, func(i, j int) bool {
+			if entries[i].Position == entries[j].Position {
+				return byID(i, j)
+			}
+			return entries[i].Position > entries[j].Position
+		})
+	}
+}
 
-	lines := make([]string, 0, len(entries))
+// buildRoleToggleFields groups entries by category (in order of first appearance) and splits
+// long groups across continuation fields so the embed stays within Discord's limits.
+func buildRoleToggleFields(entries []roleToggleEntry, config roleToggleMessageConfig) []discord.EmbedField {
+	categories := make([]string, 0)
+	grouped := make(map[string][]string)
 	for _, entry := range entries {
-		line := fmt.Sprintf("<@&%s>", entry.RoleID)
-		if entry.Description != "" {
-			line = fmt.Sprintf("%s - %s", line, entry.Description)
+		category := entry.Category
+		if category == "" {
+			category = uncategorizedRoleField
+		}
+		if _, ok := grouped[category]; !ok {
+			categories = append(categories, category)
+		}
+		grouped[category] = append(grouped[category], formatRoleToggleLine(entry, config))
+	}
+
+	fields := make([]discord.EmbedField, 0, len(categories))
+	total := 0
+	omitted := 0
+	for _, category := range categories {
+		name := truncateText(category, embedMaxFieldName)
+		for _, chunk := range chunkLines(grouped[category], embedMaxFieldValue) {
+			if len(fields) >= embedMaxFields-1 || total+len(name)+len(chunk.value) > embedMaxFieldTotal {
+				omitted += chunk.lines
+				continue
+			}
+			fields = append(fields, discord.EmbedField{Name: name, Value: chunk.value})
+			total += len(name) + len(chunk.value)
+			name = truncateText(category+" (cont.)", embedMaxFieldName)
 		}
-		lines = append(lines, line)
 	}
 
-	return lines, nil
+	if omitted > 0 {
+		fields = append(fields, discord.EmbedField{
+			Name:  "More roles",
+			Value: fmt.Sprintf("…and %d more. Use `/%s` to see them all.", omitted, commands.RoleSelfCommandName),
+		})
+	}
+
+	return fields
+}
+
+func formatRoleToggleLine(entry roleToggleEntry, config roleToggleMessageConfig) string {
+	line := fmt.Sprintf("<@&%s>", entry.RoleID)
+	if entry.Emoji != "" && !config.HideEmoji {
+		line = fmt.Sprintf("%s %s", entry.Emoji, line)
+	}
+	if entry.Description != "" {
+		line = fmt.Sprintf("%s - %s", line, entry.Description)
+	}
+	if config.ShowCounts && entry.HasCount {
+		noun := "members"
+		if entry.MemberCount == 1 {
+			noun = "member"
+		}
+		line = fmt.Sprintf("%s (%d %s)", line, entry.MemberCount, noun)
+	}
+	return truncateText(line, embedMaxFieldValue)
+}
+
+type lineChunk struct {
+	value string
+	lines int
+}
+
+func chunkLines(lines []string, limit int) []lineChunk {
+	chunks := make([]lineChunk, 0, 1)
+	current := lineChunk{}
+	for _, line := range lines {
+		if current.lines > 0 && len(current.value)+1+len(line) > limit {
+			chunks = append(chunks, current)
+			current = lineChunk{}
+		}
+		if current.lines > 0 {
+			current.value += "\n"
+		}
+		current.value += line
+		current.lines++
+	}
+	if current.lines > 0 {
+		chunks = append(chunks, current)
+	}
+	return chunks
+}
+
+func truncateText(value string, limit int) string {
+	if len(value) <= limit {
+		return value
+	}
+	runes := []rune(value)
+	for len(string(runes)) > limit-len("…") {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "…"
 }
 
 func parseRoleToggleMessageConfig(raw string) (roleToggleMessageConfig, error) {
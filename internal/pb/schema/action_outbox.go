@@ -0,0 +1,26 @@
+package schema
+
+import "github.com/pocketbase/pocketbase/core"
+
+func init() {
+	Register(actionOutboxCollection)
+}
+
+func actionOutboxCollection() *core.Collection {
+	collection := core.NewBaseCollection("action_outbox")
+	collection.Fields.Add(
+		&core.TextField{Name: "type", Required: true},
+		&core.JSONField{Name: "payload", Required: true},
+		&core.SelectField{
+			Name:     "status",
+			Required: true,
+			Values:   []string{"pending", "processing", "done", "failed"},
+		},
+		&core.TextField{Name: "note"},
+		&core.AutodateField{Name: "created", OnCreate: true},
+		&core.AutodateField{Name: "updated", OnCreate: true, OnUpdate: true},
+	)
+	collection.AddIndex("idx_action_outbox_status", false, "status, created", "")
+
+	return collection
+}
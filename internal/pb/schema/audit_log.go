@@ -0,0 +1,29 @@
+package schema
+
+import "github.com/pocketbase/pocketbase/core"
+
+func init() {
+	Register(auditLogCollection)
+}
+
+func auditLogCollection() *core.Collection {
+	collection := core.NewBaseCollection("audit_log")
+	collection.Fields.Add(
+		&core.TextField{Name: "guild_id", Required: true},
+		&core.TextField{Name: "actor_id", Required: true},
+		&core.TextField{Name: "actor_name"},
+		&core.TextField{Name: "command", Required: true},
+		&core.JSONField{Name: "options"},
+		&core.TextField{Name: "target"},
+		&core.SelectField{
+			Name:     "outcome",
+			Required: true,
+			Values:   []string{"success", "info", "decline", "warn", "error", "unknown"},
+		},
+		&core.TextField{Name: "message"},
+		&core.AutodateField{Name: "created", OnCreate: true},
+	)
+	collection.AddIndex("idx_audit_log_guild_created", false, "guild_id, created", "")
+
+	return collection
+}
@@ -0,0 +1,24 @@
+package schema
+
+import "github.com/pocketbase/pocketbase/core"
+
+func init() {
+	Register(autoRolesCollection)
+}
+
+func autoRolesCollection() *core.Collection {
+	collection := core.NewBaseCollection("auto_roles")
+	collection.Fields.Add(
+		&core.TextField{Name: "guild_id", Required: true},
+		&core.TextField{Name: "role_id", Required: true},
+		&core.SelectField{
+			Name:     "mode",
+			Required: true,
+			Values:   []string{"join", "sticky"},
+		},
+		&core.NumberField{Name: "delay_seconds"},
+		&core.BoolField{Name: "after_screening"},
+	)
+
+	return collection
+}
@@ -0,0 +1,26 @@
+package schema
+
+import "github.com/pocketbase/pocketbase/core"
+
+func init() {
+	Register(commandPermissionsCollection)
+}
+
+func commandPermissionsCollection() *core.Collection {
+	collection := core.NewBaseCollection("command_permissions")
+	collection.Fields.Add(
+		&core.TextField{Name: "guild_id", Required: true},
+		&core.TextField{Name: "path", Required: true},
+		&core.SelectField{
+			Name:     "rule",
+			Required: true,
+			Values:   []string{"disabled", "role"},
+		},
+		&core.TextField{Name: "role_id"},
+		&core.TextField{Name: "created_by"},
+		&core.AutodateField{Name: "created", OnCreate: true},
+	)
+	collection.AddIndex("idx_command_permissions_rule", true, "guild_id, path, rule, role_id", "")
+
+	return collection
+}
@@ -0,0 +1,29 @@
+package schema
+
+import "github.com/pocketbase/pocketbase/core"
+
+func init() {
+	Register(discordOutboxCollection)
+}
+
+func discordOutboxCollection() *core.Collection {
+	collection := core.NewBaseCollection("discord_outbox")
+	collection.Fields.Add(
+		&core.TextField{Name: "channel_id", Required: true},
+		&core.TextField{Name: "content", Max: 2000},
+		&core.JSONField{Name: "embeds"},
+		&core.TextField{Name: "reply_to"},
+		&core.TextField{Name: "edit_message_id"},
+		&core.SelectField{
+			Name:   "status",
+			Values: []string{"pending", "queued", "sent", "failed", "superseded"},
+		},
+		&core.TextField{Name: "message_id"},
+		&core.TextField{Name: "error"},
+		&core.AutodateField{Name: "created", OnCreate: true},
+		&core.AutodateField{Name: "updated", OnCreate: true, OnUpdate: true},
+	)
+	collection.AddIndex("idx_discord_outbox_status", false, "status", "")
+
+	return collection
+}
@@ -0,0 +1,22 @@
+package schema
+
+import "github.com/pocketbase/pocketbase/core"
+
+func init() {
+	Register(guildSettingsCollection)
+}
+
+func guildSettingsCollection() *core.Collection {
+	collection := core.NewBaseCollection("guild_settings")
+	collection.Fields.Add(
+		&core.TextField{Name: "guild_id", Required: true},
+		&core.TextField{Name: "key", Required: true},
+		&core.TextField{Name: "value"},
+		&core.TextField{Name: "updated_by"},
+		&core.AutodateField{Name: "created", OnCreate: true},
+		&core.AutodateField{Name: "updated", OnCreate: true, OnUpdate: true},
+	)
+	collection.AddIndex("idx_guild_settings_guild_key", true, "guild_id, key", "")
+
+	return collection
+}
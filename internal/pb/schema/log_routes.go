@@ -0,0 +1,23 @@
+package schema
+
+import "github.com/pocketbase/pocketbase/core"
+
+func init() {
+	Register(logRoutesCollection)
+}
+
+func logRoutesCollection() *core.Collection {
+	collection := core.NewBaseCollection("log_routes")
+	collection.Fields.Add(
+		&core.TextField{Name: "guild_id", Required: true},
+		&core.TextField{Name: "channel_id", Required: true},
+		&core.TextField{Name: "category"},
+		&core.SelectField{
+			Name:     "min_level",
+			Required: true,
+			Values:   []string{"debug", "info", "warn", "error"},
+		},
+	)
+
+	return collection
+}
@@ -0,0 +1,29 @@
+package schema
+
+import "github.com/pocketbase/pocketbase/core"
+
+func init() {
+	Register(memberEventsCollection)
+}
+
+func memberEventsCollection() *core.Collection {
+	collection := core.NewBaseCollection("member_events")
+	collection.Fields.Add(
+		&core.TextField{Name: "guild_id", Required: true},
+		&core.TextField{Name: "user_id", Required: true},
+		&core.TextField{Name: "username"},
+		&core.SelectField{
+			Name:     "type",
+			Required: true,
+			Values:   []string{"join", "leave", "kick", "ban", "unban", "role_add", "role_remove"},
+		},
+		&core.TextField{Name: "actor_id"},
+		&core.JSONField{Name: "role_ids"},
+		&core.TextField{Name: "reason"},
+		&core.DateField{Name: "account_created"},
+		&core.AutodateField{Name: "created", OnCreate: true},
+	)
+	collection.AddIndex("idx_member_events_guild_user", false, "guild_id, user_id", "")
+
+	return collection
+}
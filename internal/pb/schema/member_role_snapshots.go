@@ -0,0 +1,18 @@
+package schema
+
+import "github.com/pocketbase/pocketbase/core"
+
+func init() {
+	Register(memberRoleSnapshotsCollection)
+}
+
+func memberRoleSnapshotsCollection() *core.Collection {
+	collection := core.NewBaseCollection("member_role_snapshots")
+	collection.Fields.Add(
+		&core.TextField{Name: "guild_id", Required: true},
+		&core.TextField{Name: "user_id", Required: true},
+		&core.JSONField{Name: "role_ids"},
+	)
+
+	return collection
+}
@@ -13,6 +13,9 @@ func roleTogglesCollection() *core.Collection {
 		&core.TextField{Name: "role_id", Required: true},
 		&core.TextField{Name: "permissions", Required: true},
 		&core.TextField{Name: "description"},
+		&core.TextField{Name: "category"},
+		&core.TextField{Name: "emoji"},
+		&core.NumberField{Name: "sort_order", OnlyInt: true},
 	)
 
 	return collection
@@ -0,0 +1,24 @@
+package schema
+
+import "github.com/pocketbase/pocketbase/core"
+
+func init() {
+	Register(scheduledRolesCollection)
+}
+
+// scheduledRolesCollection holds delayed auto roles until they are due, so a restart doesn't lose them.
+func scheduledRolesCollection() *core.Collection {
+	collection := core.NewBaseCollection("scheduled_roles")
+	collection.Fields.Add(
+		&core.TextField{Name: "guild_id", Required: true},
+		&core.TextField{Name: "user_id", Required: true},
+		&core.TextField{Name: "role_id", Required: true},
+		&core.TextField{Name: "reason"},
+		&core.DateField{Name: "due_at", Required: true},
+		&core.AutodateField{Name: "created", OnCreate: true},
+	)
+	collection.AddIndex("idx_scheduled_roles_due", false, "due_at", "")
+	collection.AddIndex("idx_scheduled_roles_member", false, "guild_id, user_id", "")
+
+	return collection
+}
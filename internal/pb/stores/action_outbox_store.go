@@ -0,0 +1,173 @@
+package stores
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"antartica-bot/internal/bus"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+const (
+	outboxStatusPending    = "pending"
+	outboxStatusProcessing = "processing"
+	outboxStatusDone       = "done"
+	outboxStatusFailed     = "failed"
+
+	// Finished entries are kept for a while for inspection, then pruned by Prune.
+	outboxDoneRetention   = 24 * time.Hour
+	outboxFailedRetention = 7 * 24 * time.Hour
+	maxOutboxNoteLength   = 500
+)
+
+type ActionOutboxStore struct {
+	app    core.App
+	logger *slog.Logger
+}
+
+func NewActionOutboxStore(app core.App, logger *slog.Logger) *ActionOutboxStore {
+	if logger == nil && app != nil {
+		logger = app.Logger()
+	}
+	return &ActionOutboxStore{
+		app:    app,
+		logger: logger,
+	}
+}
+
+func (s *ActionOutboxStore) Add(ctx context.Context, action bus.DiscordAction) (string, error) {
+	if s == nil || s.app == nil {
+		return "", errors.New("action outbox store is not configured")
+	}
+
+	payload, err := bus.Encode(action)
+	if err != nil {
+		return "", err
+	}
+
+	collection, err := s.app.FindCollectionByNameOrId("action_outbox")
+	if err != nil {
+		return "", err
+	}
+
+	record := core.NewRecord(collection)
+	record.Set("type", fmt.Sprintf("%T", action))
+	record.Set("payload", types.JSONRaw(payload))
+	record.Set("status", outboxStatusPending)
+	if err := s.app.SaveWithContext(ctx, record); err != nil {
+		return "", err
+	}
+	return record.Id, nil
+}
+
+// Claim is a conditional UPDATE rather than a find-and-save, so two workers racing for the same entry
+// can't both see it pending. Record hooks don't fire for it; nothing in the bot hooks action_outbox.
+func (s *ActionOutboxStore) Claim(ctx context.Context, id string) (bool, error) {
+	result, err := s.app.DB().Update("action_outbox", dbx.Params{
+		"status":  outboxStatusProcessing,
+		"updated": types.NowDateTime(),
+	}, dbx.HashExp{
+		"id":     id,
+		"status": outboxStatusPending,
+	}).WithContext(ctx).Execute()
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+func (s *ActionOutboxStore) Complete(ctx context.Context, id string, note string) error {
+	return s.finish(ctx, id, outboxStatusDone, note)
+}
+
+func (s *ActionOutboxStore) Fail(ctx context.Context, id string, reason string) error {
+	return s.finish(ctx, id, outboxStatusFailed, reason)
+}
+
+func (s *ActionOutboxStore) finish(ctx context.Context, id string, status string, note string) error {
+	runes := []rune(note)
+	if len(runes) > maxOutboxNoteLength {
+		note = string(runes[:maxOutboxNoteLength])
+	}
+
+	record, err := s.app.FindRecordById("action_outbox", id)
+	if err != nil {
+		return err
+	}
+	record.Set("status", status)
+	record.Set("note", note)
+	return s.app.SaveWithContext(ctx, record)
+}
+
+// Recover requeues entries interrupted mid-flight and returns everything pending. The requeue is a single
+// bulk UPDATE; it runs before the worker starts, so no claim can race it.
+func (s *ActionOutboxStore) Recover(ctx context.Context) ([]bus.OutboxEntry, error) {
+	if s == nil || s.app == nil {
+		return nil, errors.New("action outbox store is not configured")
+	}
+
+	if _, err := s.app.DB().Update("action_outbox",
+		dbx.Params{"status": outboxStatusPending},
+		dbx.HashExp{"status": outboxStatusProcessing},
+	).WithContext(ctx).Execute(); err != nil {
+		return nil, err
+	}
+
+	records, err := s.app.FindRecordsByFilter("action_outbox", "status = {:status}", "created", 0, 0, dbx.Params{
+		"status": outboxStatusPending,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]bus.OutboxEntry, 0, len(records))
+	for _, record := range records {
+		raw, _ := record.Get("payload").(types.JSONRaw)
+		decoded, err := bus.Decode(raw)
+		action, ok := decoded.(bus.DiscordAction)
+		if err != nil || !ok {
+			if s.logger != nil {
+				s.logger.Warn("outbox entry unreadable", slog.String("id", record.Id), slog.Any("err", err))
+			}
+			_ = s.Fail(ctx, record.Id, "unreadable payload")
+			continue
+		}
+		entries = append(entries, bus.OutboxEntry{ID: record.Id, Action: action})
+	}
+	return entries, nil
+}
+
+// Prune deletes finished entries older than their retention. It deletes in bulk, without loading records.
+func (s *ActionOutboxStore) Prune(ctx context.Context) {
+	if s == nil || s.app == nil {
+		return
+	}
+	s.prune(ctx, outboxStatusDone, outboxDoneRetention)
+	s.prune(ctx, outboxStatusFailed, outboxFailedRetention)
+}
+
+func (s *ActionOutboxStore) prune(ctx context.Context, status string, retention time.Duration) {
+	cutoff, err := types.ParseDateTime(time.Now().Add(-retention))
+	if err != nil {
+		return
+	}
+	_, err = s.app.DB().Delete("action_outbox", dbx.And(
+		dbx.HashExp{"status": status},
+		dbx.NewExp("updated < {:cutoff}", dbx.Params{"cutoff": cutoff.String()}),
+	)).WithContext(ctx).Execute()
+	if err != nil && s.logger != nil {
+		s.logger.Warn("outbox prune failed", slog.String("status", status), slog.Any("err", err))
+	}
+}
+
+var _ bus.ActionOutbox = (*ActionOutboxStore)(nil)
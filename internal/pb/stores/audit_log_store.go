@@ -0,0 +1,123 @@
+package stores
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+
+	"antartica-bot/internal/bus"
+
+	"github.com/disgoorg/snowflake/v2"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+const (
+	defaultAuditLimit = 10
+	maxAuditLimit     = 50
+)
+
+type AuditLogStore struct {
+	app    core.App
+	logger *slog.Logger
+}
+
+func NewAuditLogStore(app core.App, logger *slog.Logger) *AuditLogStore {
+	if logger == nil && app != nil {
+		logger = app.Logger()
+	}
+	return &AuditLogStore{
+		app:    app,
+		logger: logger,
+	}
+}
+
+func (s *AuditLogStore) RecordAudit(ctx context.Context, entry bus.AuditEntry) error {
+	if s == nil || s.app == nil {
+		return errors.New("audit log store is not configured")
+	}
+
+	collection, err := s.app.FindCollectionByNameOrId("audit_log")
+	if err != nil {
+		return err
+	}
+
+	outcome := strings.TrimSpace(entry.Outcome)
+	if outcome == "" {
+		outcome = "unknown"
+	}
+
+	record := core.NewRecord(collection)
+	record.Set("guild_id", entry.GuildID.String())
+	record.Set("actor_id", entry.ActorID.String())
+	record.Set("actor_name", strings.TrimSpace(entry.ActorName))
+	record.Set("command", strings.TrimSpace(entry.Command))
+	record.Set("options", entry.Options)
+	record.Set("target", strings.TrimSpace(entry.Target))
+	record.Set("outcome", outcome)
+	record.Set("message", strings.TrimSpace(entry.Message))
+
+	return s.app.SaveWithContext(ctx, record)
+}
+
+func (s *AuditLogStore) ListAuditEntries(ctx context.Context, guildID snowflake.ID, filter bus.AuditFilter) ([]bus.AuditEntry, error) {
+	if s == nil || s.app == nil {
+		return nil, errors.New("audit log store is not configured")
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultAuditLimit
+	}
+	if limit > maxAuditLimit {
+		limit = maxAuditLimit
+	}
+
+	exprs := []dbx.Expression{dbx.HashExp{"guild_id": guildID.String()}}
+	if filter.ActorID != nil {
+		exprs = append(exprs, dbx.HashExp{"actor_id": filter.ActorID.String()})
+	}
+	if command := strings.TrimSpace(filter.Command); command != "" {
+		if !strings.HasPrefix(command, "/") {
+			command = "/" + command
+		}
+		exprs = append(exprs, dbx.Like("command", command).Match(false, true))
+	}
+	if outcome := strings.TrimSpace(filter.Outcome); outcome != "" {
+		exprs = append(exprs, dbx.HashExp{"outcome": outcome})
+	}
+
+	query := s.app.RecordQuery("audit_log").
+		WithContext(ctx).
+		AndWhere(dbx.And(exprs...)).
+		OrderBy("created DESC").
+		Limit(int64(limit))
+
+	var found []*core.Record
+	if err := query.All(&found); err != nil {
+		return nil, err
+	}
+
+	entries := make([]bus.AuditEntry, 0, len(found))
+	for _, record := range found {
+		actorID, _ := snowflake.Parse(record.GetString("actor_id"))
+		options := map[string]string{}
+		_ = record.UnmarshalJSONField("options", &options)
+		entries = append(entries, bus.AuditEntry{
+			GuildID:   guildID,
+			ActorID:   actorID,
+			ActorName: record.GetString("actor_name"),
+			Command:   record.GetString("command"),
+			Options:   options,
+			Target:    record.GetString("target"),
+			Outcome:   record.GetString("outcome"),
+			Message:   record.GetString("message"),
+			CreatedAt: record.GetDateTime("created").Time(),
+		})
+	}
+
+	return entries, nil
+}
+
+var _ bus.AuditLogStore = (*AuditLogStore)(nil)
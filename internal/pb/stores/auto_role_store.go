@@ -0,0 +1,168 @@
+package stores
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"antartica-bot/internal/bus"
+
+	"github.com/disgoorg/snowflake/v2"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+type AutoRoleStore struct {
+	app    core.App
+	logger *slog.Logger
+}
+
+func NewAutoRoleStore(app core.App, logger *slog.Logger) *AutoRoleStore {
+	if logger == nil && app != nil {
+		logger = app.Logger()
+	}
+	return &AutoRoleStore{
+		app:    app,
+		logger: logger,
+	}
+}
+
+func (s *AutoRoleStore) UpsertAutoRole(ctx context.Context, guildID snowflake.ID, roleID snowflake.ID, mode string, delay time.Duration, afterScreening bool) (bool, error) {
+	if s == nil || s.app == nil {
+		return false, errors.New("auto role store is not configured")
+	}
+
+	mode = strings.TrimSpace(mode)
+	if mode != bus.AutoRoleModeJoin && mode != bus.AutoRoleModeSticky {
+		return false, fmt.Errorf("unknown auto role mode %q", mode)
+	}
+	delaySeconds := int(delay / time.Second)
+	if delaySeconds < 0 {
+		delaySeconds = 0
+	}
+
+	records, err := s.app.FindAllRecords("auto_roles", dbx.HashExp{
+		"guild_id": guildID.String(),
+		"role_id":  roleID.String(),
+		"mode":     mode,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	if len(records) > 0 {
+		record := records[0]
+		record.Set("delay_seconds", delaySeconds)
+		record.Set("after_screening", afterScreening)
+		if err := s.app.SaveWithContext(ctx, record); err != nil {
+			return false, err
+		}
+		return false, nil
+	}
+
+	collection, err := s.app.FindCollectionByNameOrId("auto_roles")
+	if err != nil {
+		return false, err
+	}
+
+	record := core.NewRecord(collection)
+	record.Set("guild_id", guildID.String())
+	record.Set("role_id", roleID.String())
+	record.Set("mode", mode)
+	record.Set("delay_seconds", delaySeconds)
+	record.Set("after_screening", afterScreening)
+
+	if err := s.app.SaveWithContext(ctx, record); err != nil {
+		return false, err
+	}
+
+	if s.logger != nil {
+		s.logger.Info(
+			"auto role added",
+			slog.String("guild_id", guildID.String()),
+			slog.String("role_id", roleID.String()),
+			slog.String("mode", mode),
+		)
+	}
+
+	return true, nil
+}
+
+func (s *AutoRoleStore) RemoveAutoRole(ctx context.Context, guildID snowflake.ID, roleID snowflake.ID, mode string) (int, error) {
+	if s == nil || s.app == nil {
+		return 0, errors.New("auto role store is not configured")
+	}
+
+	filter := dbx.HashExp{
+		"guild_id": guildID.String(),
+		"role_id":  roleID.String(),
+	}
+	if mode = strings.TrimSpace(mode); mode != "" {
+		filter["mode"] = mode
+	}
+
+	records, err := s.app.FindAllRecords("auto_roles", filter)
+	if err != nil {
+		return 0, err
+	}
+	if len(records) == 0 {
+		return 0, nil
+	}
+
+	deleted := 0
+	for _, record := range records {
+		if err := s.app.DeleteWithContext(ctx, record); err != nil {
+			return deleted, fmt.Errorf("delete auto role %s: %w", record.Id, err)
+		}
+		deleted++
+	}
+
+	if s.logger != nil {
+		s.logger.Info(
+			"auto roles removed",
+			slog.String("guild_id", guildID.String()),
+			slog.String("role_id", roleID.String()),
+			slog.Int("count", deleted),
+		)
+	}
+
+	return deleted, nil
+}
+
+func (s *AutoRoleStore) ListAutoRoles(ctx context.Context, guildID snowflake.ID) ([]bus.AutoRole, error) {
+	if s == nil || s.app == nil {
+		return nil, errors.New("auto role store is not configured")
+	}
+
+	records, err := s.app.FindAllRecords("auto_roles", dbx.HashExp{
+		"guild_id": guildID.String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	autoRoles := make([]bus.AutoRole, 0, len(records))
+	for _, record := range records {
+		roleID, err := snowflake.Parse(record.GetString("role_id"))
+		if err != nil {
+			if s.logger != nil {
+				s.logger.Warn("invalid auto role role_id", slog.String("role_id", record.GetString("role_id")), slog.String("record_id", record.Id))
+			}
+			continue
+		}
+
+		autoRoles = append(autoRoles, bus.AutoRole{
+			RoleID:         roleID,
+			Mode:           strings.TrimSpace(record.GetString("mode")),
+			Delay:          time.Duration(record.GetInt("delay_seconds")) * time.Second,
+			AfterScreening: record.GetBool("after_screening"),
+		})
+	}
+
+	return autoRoles, nil
+}
+
+var _ bus.AutoRoleStore = (*AutoRoleStore)(nil)
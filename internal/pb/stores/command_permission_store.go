@@ -0,0 +1,173 @@
+package stores
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"antartica-bot/internal/bus"
+
+	"github.com/disgoorg/snowflake/v2"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// commandRulesTTL bounds how long cached rules live, like guild settings: writes through the store refresh
+// the cache immediately and dashboard edits show up once the entry expires.
+const commandRulesTTL = time.Minute
+
+type CommandPermissionStore struct {
+	app    core.App
+	logger *slog.Logger
+
+	mu    sync.Mutex
+	cache map[snowflake.ID]cachedCommandRules
+}
+
+type cachedCommandRules struct {
+	rules    []bus.CommandRule
+	loadedAt time.Time
+}
+
+func NewCommandPermissionStore(app core.App, logger *slog.Logger) *CommandPermissionStore {
+	if logger == nil && app != nil {
+		logger = app.Logger()
+	}
+	return &CommandPermissionStore{
+		app:    app,
+		logger: logger,
+		cache:  make(map[snowflake.ID]cachedCommandRules),
+	}
+}
+
+// CommandRules returns the guild's rules. The slice is shared with the cache and must not be modified.
+func (s *CommandPermissionStore) CommandRules(ctx context.Context, guildID snowflake.ID) ([]bus.CommandRule, error) {
+	if s == nil || s.app == nil {
+		return nil, errors.New("command permission store is not configured")
+	}
+
+	s.mu.Lock()
+	cached, ok := s.cache[guildID]
+	s.mu.Unlock()
+	if ok && time.Since(cached.loadedAt) < commandRulesTTL {
+		return cached.rules, nil
+	}
+
+	records, err := s.app.FindAllRecords("command_permissions", dbx.HashExp{
+		"guild_id": guildID.String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rules := make([]bus.CommandRule, 0, len(records))
+	for _, record := range records {
+		rule := bus.CommandRule{
+			Path: record.GetString("path"),
+			Kind: bus.CommandRuleKind(record.GetString("rule")),
+		}
+		if raw := record.GetString("role_id"); raw != "" {
+			roleID, err := snowflake.Parse(raw)
+			if err != nil {
+				continue
+			}
+			rule.RoleID = roleID
+		}
+		rules = append(rules, rule)
+	}
+
+	s.mu.Lock()
+	s.cache[guildID] = cachedCommandRules{rules: rules, loadedAt: time.Now()}
+	s.mu.Unlock()
+
+	return rules, nil
+}
+
+func (s *CommandPermissionStore) AddCommandRule(ctx context.Context, guildID snowflake.ID, rule bus.CommandRule, createdBy snowflake.ID) (bool, error) {
+	if s == nil || s.app == nil {
+		return false, errors.New("command permission store is not configured")
+	}
+	defer s.invalidate(guildID)
+
+	records, err := s.findRule(guildID, rule)
+	if err != nil {
+		return false, err
+	}
+	if len(records) > 0 {
+		return false, nil
+	}
+
+	collection, err := s.app.FindCollectionByNameOrId("command_permissions")
+	if err != nil {
+		return false, err
+	}
+	record := core.NewRecord(collection)
+	record.Set("guild_id", guildID.String())
+	record.Set("path", rule.Path)
+	record.Set("rule", string(rule.Kind))
+	record.Set("role_id", roleIDValue(rule.RoleID))
+	if createdBy != 0 {
+		record.Set("created_by", createdBy.String())
+	}
+
+	if err := s.app.SaveWithContext(ctx, record); err != nil {
+		return false, err
+	}
+
+	if s.logger != nil {
+		s.logger.Info(
+			"command rule added",
+			slog.String("guild_id", guildID.String()),
+			slog.String("path", rule.Path),
+			slog.String("rule", string(rule.Kind)),
+			slog.String("role_id", roleIDValue(rule.RoleID)),
+		)
+	}
+	return true, nil
+}
+
+func (s *CommandPermissionStore) RemoveCommandRule(ctx context.Context, guildID snowflake.ID, rule bus.CommandRule) (bool, error) {
+	if s == nil || s.app == nil {
+		return false, errors.New("command permission store is not configured")
+	}
+	defer s.invalidate(guildID)
+
+	records, err := s.findRule(guildID, rule)
+	if err != nil {
+		return false, err
+	}
+
+	for _, record := range records {
+		if err := s.app.DeleteWithContext(ctx, record); err != nil {
+			return false, fmt.Errorf("delete command rule %s: %w", record.Id, err)
+		}
+	}
+	return len(records) > 0, nil
+}
+
+func (s *CommandPermissionStore) findRule(guildID snowflake.ID, rule bus.CommandRule) ([]*core.Record, error) {
+	return s.app.FindAllRecords("command_permissions", dbx.HashExp{
+		"guild_id": guildID.String(),
+		"path":     rule.Path,
+		"rule":     string(rule.Kind),
+		"role_id":  roleIDValue(rule.RoleID),
+	})
+}
+
+func (s *CommandPermissionStore) invalidate(guildID snowflake.ID) {
+	s.mu.Lock()
+	delete(s.cache, guildID)
+	s.mu.Unlock()
+}
+
+func roleIDValue(roleID snowflake.ID) string {
+	if roleID == 0 {
+		return ""
+	}
+	return roleID.String()
+}
+
+var _ bus.CommandPermissionStore = (*CommandPermissionStore)(nil)
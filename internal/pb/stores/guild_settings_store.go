@@ -0,0 +1,154 @@
+package stores
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"antartica-bot/internal/bus"
+
+	"github.com/disgoorg/snowflake/v2"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// guildSettingsTTL bounds how long cached settings live. Writes through the store refresh the cache
+// immediately; edits made in the PocketBase dashboard show up once the entry expires.
+const guildSettingsTTL = time.Minute
+
+type GuildSettingsStore struct {
+	app    core.App
+	logger *slog.Logger
+
+	mu    sync.Mutex
+	cache map[snowflake.ID]cachedGuildSettings
+}
+
+type cachedGuildSettings struct {
+	values   map[string]string
+	loadedAt time.Time
+}
+
+func NewGuildSettingsStore(app core.App, logger *slog.Logger) *GuildSettingsStore {
+	if logger == nil && app != nil {
+		logger = app.Logger()
+	}
+	return &GuildSettingsStore{
+		app:    app,
+		logger: logger,
+		cache:  make(map[snowflake.ID]cachedGuildSettings),
+	}
+}
+
+// GuildSettings returns the guild's stored values. The map is shared with the cache and must not be modified.
+func (s *GuildSettingsStore) GuildSettings(ctx context.Context, guildID snowflake.ID) (map[string]string, error) {
+	if s == nil || s.app == nil {
+		return nil, errors.New("guild settings store is not configured")
+	}
+
+	s.mu.Lock()
+	cached, ok := s.cache[guildID]
+	s.mu.Unlock()
+	if ok && time.Since(cached.loadedAt) < guildSettingsTTL {
+		return cached.values, nil
+	}
+
+	records, err := s.app.FindAllRecords("guild_settings", dbx.HashExp{
+		"guild_id": guildID.String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]string, len(records))
+	for _, record := range records {
+		values[record.GetString("key")] = record.GetString("value")
+	}
+
+	s.mu.Lock()
+	s.cache[guildID] = cachedGuildSettings{values: values, loadedAt: time.Now()}
+	s.mu.Unlock()
+
+	return values, nil
+}
+
+func (s *GuildSettingsStore) SetGuildSetting(ctx context.Context, guildID snowflake.ID, key string, value string, updatedBy snowflake.ID) error {
+	if s == nil || s.app == nil {
+		return errors.New("guild settings store is not configured")
+	}
+	defer s.invalidate(guildID)
+
+	key = strings.ToLower(strings.TrimSpace(key))
+	records, err := s.app.FindAllRecords("guild_settings", dbx.HashExp{
+		"guild_id": guildID.String(),
+		"key":      key,
+	})
+	if err != nil {
+		return err
+	}
+
+	var record *core.Record
+	if len(records) > 0 {
+		record = records[0]
+	} else {
+		collection, err := s.app.FindCollectionByNameOrId("guild_settings")
+		if err != nil {
+			return err
+		}
+		record = core.NewRecord(collection)
+		record.Set("guild_id", guildID.String())
+		record.Set("key", key)
+	}
+	record.Set("value", value)
+	if updatedBy != 0 {
+		record.Set("updated_by", updatedBy.String())
+	}
+
+	if err := s.app.SaveWithContext(ctx, record); err != nil {
+		return err
+	}
+
+	if s.logger != nil {
+		s.logger.Info(
+			"guild setting changed",
+			slog.String("guild_id", guildID.String()),
+			slog.String("key", key),
+			slog.String("value", value),
+		)
+	}
+	return nil
+}
+
+func (s *GuildSettingsStore) ResetGuildSetting(ctx context.Context, guildID snowflake.ID, key string) (bool, error) {
+	if s == nil || s.app == nil {
+		return false, errors.New("guild settings store is not configured")
+	}
+	defer s.invalidate(guildID)
+
+	records, err := s.app.FindAllRecords("guild_settings", dbx.HashExp{
+		"guild_id": guildID.String(),
+		"key":      strings.ToLower(strings.TrimSpace(key)),
+	})
+	if err != nil {
+		return false, err
+	}
+
+	for _, record := range records {
+		if err := s.app.DeleteWithContext(ctx, record); err != nil {
+			return false, fmt.Errorf("delete guild setting %s: %w", record.Id, err)
+		}
+	}
+	return len(records) > 0, nil
+}
+
+func (s *GuildSettingsStore) invalidate(guildID snowflake.ID) {
+	s.mu.Lock()
+	delete(s.cache, guildID)
+	s.mu.Unlock()
+}
+
+var _ bus.GuildSettingsStore = (*GuildSettingsStore)(nil)
@@ -0,0 +1,163 @@
+package stores
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"antartica-bot/internal/bus"
+
+	"github.com/disgoorg/snowflake/v2"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+type LogRouteStore struct {
+	app    core.App
+	logger *slog.Logger
+}
+
+func NewLogRouteStore(app core.App, logger *slog.Logger) *LogRouteStore {
+	if logger == nil && app != nil {
+		logger = app.Logger()
+	}
+	return &LogRouteStore{
+		app:    app,
+		logger: logger,
+	}
+}
+
+func (s *LogRouteStore) UpsertLogRoute(ctx context.Context, guildID snowflake.ID, channelID snowflake.ID, category string, minLevel bus.LogLevel) (bool, error) {
+	if s == nil || s.app == nil {
+		return false, errors.New("log route store is not configured")
+	}
+
+	category = normalizeLogCategory(category)
+	if minLevel == "" {
+		minLevel = bus.LogInfo
+	}
+
+	records, err := s.app.FindAllRecords("log_routes", dbx.HashExp{
+		"guild_id":   guildID.String(),
+		"channel_id": channelID.String(),
+		"category":   category,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	if len(records) > 0 {
+		record := records[0]
+		record.Set("min_level", string(minLevel))
+		if err := s.app.SaveWithContext(ctx, record); err != nil {
+			return false, err
+		}
+		return false, nil
+	}
+
+	collection, err := s.app.FindCollectionByNameOrId("log_routes")
+	if err != nil {
+		return false, err
+	}
+
+	record := core.NewRecord(collection)
+	record.Set("guild_id", guildID.String())
+	record.Set("channel_id", channelID.String())
+	record.Set("category", category)
+	record.Set("min_level", string(minLevel))
+
+	if err := s.app.SaveWithContext(ctx, record); err != nil {
+		return false, err
+	}
+
+	if s.logger != nil {
+		s.logger.Info(
+			"log route added",
+			slog.String("guild_id", guildID.String()),
+			slog.String("channel_id", channelID.String()),
+			slog.String("category", category),
+			slog.String("min_level", string(minLevel)),
+		)
+	}
+
+	return true, nil
+}
+
+func (s *LogRouteStore) RemoveLogRoute(ctx context.Context, guildID snowflake.ID, channelID snowflake.ID, category string) (int, error) {
+	if s == nil || s.app == nil {
+		return 0, errors.New("log route store is not configured")
+	}
+
+	// An empty category removes every route for the channel, not just the catch-all one.
+	filter := dbx.HashExp{
+		"guild_id":   guildID.String(),
+		"channel_id": channelID.String(),
+	}
+	if category = normalizeLogCategory(category); category != "" {
+		filter["category"] = category
+	}
+	records, err := s.app.FindAllRecords("log_routes", filter)
+	if err != nil {
+		return 0, err
+	}
+	if len(records) == 0 {
+		return 0, nil
+	}
+
+	deleted := 0
+	for _, record := range records {
+		if err := s.app.DeleteWithContext(ctx, record); err != nil {
+			return deleted, fmt.Errorf("delete log route %s: %w", record.Id, err)
+		}
+		deleted++
+	}
+
+	if s.logger != nil {
+		s.logger.Info(
+			"log routes removed",
+			slog.String("guild_id", guildID.String()),
+			slog.String("channel_id", channelID.String()),
+			slog.Int("count", deleted),
+		)
+	}
+
+	return deleted, nil
+}
+
+func (s *LogRouteStore) ListLogRoutes(ctx context.Context, guildID snowflake.ID) ([]bus.LogRoute, error) {
+	if s == nil || s.app == nil {
+		return nil, errors.New("log route store is not configured")
+	}
+
+	records, err := s.app.FindAllRecords("log_routes", dbx.HashExp{
+		"guild_id": guildID.String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	routes := make([]bus.LogRoute, 0, len(records))
+	for _, record := range records {
+		channelID, err := snowflake.Parse(record.GetString("channel_id"))
+		if err != nil {
+			continue
+		}
+		routes = append(routes, bus.LogRoute{
+			ChannelID: channelID,
+			Category:  record.GetString("category"),
+			MinLevel:  bus.LogLevel(record.GetString("min_level")),
+		})
+	}
+
+	return routes, nil
+}
+
+// normalizeLogCategory lowercases categories so routes match LogEvent categories regardless of case.
+// An empty category routes every category.
+func normalizeLogCategory(category string) string {
+	return strings.ToLower(strings.TrimSpace(category))
+}
+
+var _ bus.LogRouteStore = (*LogRouteStore)(nil)
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"strings"
 
 	"antartica-bot/internal/bus"
 
@@ -28,7 +29,7 @@ func NewRoleToggleStore(app core.App, logger *slog.Logger) *RoleToggleStore {
 	}
 }
 
-func (s *RoleToggleStore) UpsertRoleToggle(ctx context.Context, guildID snowflake.ID, roleID snowflake.ID, permissions string, description *string) (bool, error) {
+func (s *RoleToggleStore) UpsertRoleToggle(ctx context.Context, guildID snowflake.ID, roleID snowflake.ID, permissions string, details bus.RoleToggleDetails) (bool, error) {
 	if s == nil || s.app == nil {
 		return false, errors.New("role toggle store is not configured")
 	}
@@ -44,9 +45,7 @@ func (s *RoleToggleStore) UpsertRoleToggle(ctx context.Context, guildID snowflak
 	if len(records) > 0 {
 		record := records[0]
 		record.Set("permissions", permissions)
-		if description != nil {
-			record.Set("description", *description)
-		}
+		applyRoleToggleDetails(record, details)
 		if err := s.app.SaveWithContext(ctx, record); err != nil {
 			return false, err
 		}
@@ -62,9 +61,7 @@ func (s *RoleToggleStore) UpsertRoleToggle(ctx context.Context, guildID snowflak
 	record.Set("guild_id", guildID.String())
 	record.Set("role_id", roleID.String())
 	record.Set("permissions", permissions)
-	if description != nil {
-		record.Set("description", *description)
-	}
+	applyRoleToggleDetails(record, details)
 
 	if err := s.app.SaveWithContext(ctx, record); err != nil {
 		return false, err
@@ -144,10 +141,28 @@ func (s *RoleToggleStore) ListRoleToggles(ctx context.Context, guildID snowflake
 			RoleID:      roleID,
 			Permissions: record.GetString("permissions"),
 			Description: record.GetString("description"),
+			Category:    strings.TrimSpace(record.GetString("category")),
+			Emoji:       strings.TrimSpace(record.GetString("emoji")),
+			SortOrder:   record.GetInt("sort_order"),
 		})
 	}
 
 	return toggles, nil
 }
 
+func applyRoleToggleDetails(record *core.Record, details bus.RoleToggleDetails) {
+	if details.Description != nil {
+		record.Set("description", strings.TrimSpace(*details.Description))
+	}
+	if details.Category != nil {
+		record.Set("category", strings.TrimSpace(*details.Category))
+	}
+	if details.Emoji != nil {
+		record.Set("emoji", strings.TrimSpace(*details.Emoji))
+	}
+	if details.SortOrder != nil {
+		record.Set("sort_order", *details.SortOrder)
+	}
+}
+
 var _ bus.RoleToggleStore = (*RoleToggleStore)(nil)
@@ -0,0 +1,187 @@
+package settings
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"antartica-bot/internal/bus"
+
+	"github.com/disgoorg/snowflake/v2"
+)
+
+// Kind decides how a setting's value is parsed and shown.
+type Kind string
+
+const (
+	KindBool    Kind = "bool"
+	KindColor   Kind = "color"
+	KindChannel Kind = "channel"
+)
+
+// Known setting keys.
+const (
+	ReactionsCountSelf = "reactions.count_self"
+	EmbedColor         = "embed.color"
+	LogDefaultChannel  = "log.default_channel"
+)
+
+// Key describes a per-guild setting. Default is used while a guild hasn't set the key; empty means unset.
+type Key struct {
+	Name        string
+	Kind        Kind
+	Description string
+	Default     string
+}
+
+var keys = []Key{
+	{
+		Name:        ReactionsCountSelf,
+		Kind:        KindBool,
+		Description: "Count reactions members add to their own messages on tracked emojis",
+		Default:     "false",
+	},
+	{
+		Name:        EmbedColor,
+		Kind:        KindColor,
+		Description: "Colour of managed embeds such as leaderboards and role lists, e.g. #5865F2",
+	},
+	{
+		Name:        LogDefaultChannel,
+		Kind:        KindChannel,
+		Description: "Channel for log events no /log route matches (info and above)",
+	},
+}
+
+// Keys returns every known setting, sorted by name.
+func Keys() []Key {
+	sorted := append([]Key(nil), keys...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Name < sorted[j].Name
+	})
+	return sorted
+}
+
+func Lookup(name string) (Key, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for _, key := range keys {
+		if key.Name == name {
+			return key, true
+		}
+	}
+	return Key{}, false
+}
+
+// Normalize checks raw against the key's kind and returns the value to store.
+func (k Key) Normalize(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", fmt.Errorf("%s needs a value", k.Name)
+	}
+
+	switch k.Kind {
+	case KindBool:
+		switch strings.ToLower(raw) {
+		case "true", "yes", "on", "1", "enabled":
+			return "true", nil
+		case "false", "no", "off", "0", "disabled":
+			return "false", nil
+		}
+		return "", fmt.Errorf("%s must be true or false", k.Name)
+	case KindColor:
+		hex := strings.TrimPrefix(strings.TrimPrefix(strings.ToLower(raw), "#"), "0x")
+		if len(hex) != 6 {
+			return "", fmt.Errorf("%s must be a hex colour like #5865F2", k.Name)
+		}
+		if _, err := strconv.ParseUint(hex, 16, 32); err != nil {
+			return "", fmt.Errorf("%s must be a hex colour like #5865F2", k.Name)
+		}
+		return "#" + hex, nil
+	case KindChannel:
+		id := strings.TrimSuffix(strings.TrimPrefix(raw, "<#"), ">")
+		if _, err := snowflake.Parse(id); err != nil {
+			return "", fmt.Errorf("%s must be a channel mention or ID", k.Name)
+		}
+		return id, nil
+	}
+	return raw, nil
+}
+
+// Display formats a stored value for Discord messages.
+func (k Key) Display(value string) string {
+	if value == "" {
+		return "not set"
+	}
+	if k.Kind == KindChannel {
+		return "<#" + value + ">"
+	}
+	return "`" + value + "`"
+}
+
+// Guild is one guild's settings with defaults applied to keys it hasn't set.
+type Guild struct {
+	values map[string]string
+}
+
+// Load reads a guild's settings. A nil store or a failed lookup yields the defaults, so callers can always
+// read settings without handling errors; the error is returned for logging.
+func Load(ctx context.Context, store bus.GuildSettingsStore, guildID snowflake.ID) (Guild, error) {
+	if store == nil || guildID == 0 {
+		return Guild{}, nil
+	}
+	values, err := store.GuildSettings(ctx, guildID)
+	if err != nil {
+		return Guild{}, err
+	}
+	return Guild{values: values}, nil
+}
+
+// Get returns the guild's value for name, or the key's default.
+func (g Guild) Get(name string) string {
+	if value, ok := g.values[name]; ok {
+		return value
+	}
+	if key, ok := Lookup(name); ok {
+		return key.Default
+	}
+	return ""
+}
+
+// IsSet reports whether the guild set name itself rather than relying on the default.
+func (g Guild) IsSet(name string) bool {
+	_, ok := g.values[name]
+	return ok
+}
+
+func (g Guild) Bool(name string) bool {
+	value, _ := strconv.ParseBool(g.Get(name))
+	return value
+}
+
+// Color returns the colour as an embed colour value, or false when unset.
+func (g Guild) Color(name string) (int, bool) {
+	raw := strings.TrimPrefix(g.Get(name), "#")
+	if raw == "" {
+		return 0, false
+	}
+	value, err := strconv.ParseUint(raw, 16, 32)
+	if err != nil {
+		return 0, false
+	}
+	return int(value), true
+}
+
+// Channel returns the channel ID, or false when unset.
+func (g Guild) Channel(name string) (snowflake.ID, bool) {
+	raw := g.Get(name)
+	if raw == "" {
+		return 0, false
+	}
+	id, err := snowflake.Parse(raw)
+	if err != nil {
+		return 0, false
+	}
+	return id, true
+}